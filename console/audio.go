@@ -0,0 +1,67 @@
+package console
+
+import (
+	"fmt"
+)
+
+// RenderAudio - renders seconds of music starting at pattern into mono 16 bit samples
+// rendering uses a copy of the console's sfx & music so live playback is unaffected
+func RenderAudio(pattern int, seconds float64) ([]int16, error) {
+	if pattern < 0 || pattern >= _musicCount {
+		return nil, fmt.Errorf("Error rendering audio - pattern outside range: %d", pattern)
+	}
+	s, err := offlineSynth()
+	if err != nil {
+		return nil, err
+	}
+	s.playMusic(pattern, 0, 0)
+	return s.renderOffline(seconds)
+}
+
+// RenderSfx - renders seconds of sfx n into mono 16 bit samples
+func RenderSfx(n int, seconds float64) ([]int16, error) {
+	if n < 0 || n >= _sfxCount {
+		return nil, fmt.Errorf("Error rendering sfx - number outside range: %d", n)
+	}
+	s, err := offlineSynth()
+	if err != nil {
+		return nil, err
+	}
+	s.play(n, 0, 0)
+	return s.renderOffline(seconds)
+}
+
+func offlineSynth() (*synth, error) {
+	if _console.synth == nil {
+		return nil, fmt.Errorf("Error rendering audio - audio not initialised")
+	}
+	return _console.synth.clone(), nil
+}
+
+// clone - returns a new synth with the same sfx & music definitions
+func (s *synth) clone() *synth {
+	s.Lock()
+	defer s.Unlock()
+	c := newSynth()
+	c.sfx = s.sfx
+	c.music = s.music
+	return c
+}
+
+// renderOffline - renders a frame at a time advancing the music as the console would
+func (s *synth) renderOffline(seconds float64) ([]int16, error) {
+	if seconds <= 0 {
+		return nil, fmt.Errorf("Error rendering audio - invalid duration: %f", seconds)
+	}
+	samples := make([]int16, int(seconds*_sampleRate))
+	frameSamples := _sampleRate / _fps
+	for pos := 0; pos < len(samples); pos += frameSamples {
+		end := pos + frameSamples
+		if end > len(samples) {
+			end = len(samples)
+		}
+		s.render(samples[pos:end])
+		s.updateMusic(frameSamples)
+	}
+	return samples, nil
+}
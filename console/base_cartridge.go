@@ -28,9 +28,78 @@ func (bc *BaseCartridge) initPb(pb PixelBuffer) {
 	bc.PixelBuffer = pb
 }
 
-func (bc *BaseCartridge) Btn(id int) bool {
+// Btn - is button pressed, player defaults to 0
+func (bc *BaseCartridge) Btn(id int, player ...int) bool {
 	// access runtime button mappings
-	//return _console.Btn(id)
-	// TODO
-	return false
+	return _console.Btn(id, player...)
+}
+
+// Btnp - was button pressed this frame, player defaults to 0
+func (bc *BaseCartridge) Btnp(id int, player ...int) bool {
+	return _console.Btnp(id, player...)
+}
+
+// Stat - query console state such as mouse position
+func (bc *BaseCartridge) Stat(n int) int {
+	return _console.Stat(n)
+}
+
+// Sfx - play sound effect n on channel from note offset
+func (bc *BaseCartridge) Sfx(n, channel, offset int) {
+	_console.Sfx(n, channel, offset)
+}
+
+// SetSfx - define sound effect n
+func (bc *BaseCartridge) SetSfx(n int, sfx SfxPattern) error {
+	return _console.SetSfx(n, sfx)
+}
+
+// Music - play music from pattern n, -1 stops music
+func (bc *BaseCartridge) Music(n, fadeMs, channelMask int) {
+	_console.Music(n, fadeMs, channelMask)
+}
+
+// SetMusic - define music pattern n
+func (bc *BaseCartridge) SetMusic(n int, pattern MusicPattern) error {
+	return _console.SetMusic(n, pattern)
+}
+
+// Sin - sine of x turns, inverted for screen coords
+func (bc *BaseCartridge) Sin(x float64) float64 {
+	return _console.Sin(x)
+}
+
+// Cos - cosine of x turns
+func (bc *BaseCartridge) Cos(x float64) float64 {
+	return _console.Cos(x)
+}
+
+// Atan2 - angle in turns (0..1) of vector dx,dy
+func (bc *BaseCartridge) Atan2(dx, dy float64) float64 {
+	return _console.Atan2(dx, dy)
+}
+
+// Rnd - random number from 0 up to but not including max
+func (bc *BaseCartridge) Rnd(max float64) float64 {
+	return _console.Rnd(max)
+}
+
+// Srand - seed the console random number generator
+func (bc *BaseCartridge) Srand(seed int64) {
+	_console.Srand(seed)
+}
+
+// Mid - middle value of x, y & z
+func (bc *BaseCartridge) Mid(x, y, z float64) float64 {
+	return _console.Mid(x, y, z)
+}
+
+// Flr - largest integer less than or equal to x
+func (bc *BaseCartridge) Flr(x float64) float64 {
+	return _console.Flr(x)
+}
+
+// Sgn - sign of x, 1 for zero or positive and -1 for negative
+func (bc *BaseCartridge) Sgn(x float64) float64 {
+	return _console.Sgn(x)
 }
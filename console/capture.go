@@ -0,0 +1,34 @@
+//go:build !js
+// +build !js
+
+package console
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+)
+
+// defaultCaptureDir - captures are saved to pico-go/captures in the home dir
+func defaultCaptureDir() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		home = os.TempDir()
+	}
+	return filepath.Join(home, "pico-go", "captures")
+}
+
+// saveCapture - writes a screenshot, video or replay to filename
+func saveCapture(filename string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		return fmt.Errorf("Error creating capture dir: %s", err)
+	}
+	if err := ioutil.WriteFile(filename, data, 0644); err != nil {
+		return fmt.Errorf("Error writing capture file: %s", err)
+	}
+	return nil
+}
+
+// listenForMessages - only carts running in the editor receive its messages
+func listenForMessages() {}
@@ -0,0 +1,54 @@
+//go:build js
+// +build js
+
+package console
+
+import (
+	"encoding/base64"
+	"fmt"
+	"path/filepath"
+
+	"github.com/gopherjs/gopherjs/js"
+)
+
+/*
+	Carts compiled with GopherJS run in an iframe of the editor and can't
+	write files. Captures are posted to the editor window as "capture"
+	messages, the editor backend writes them to its captures dir using
+	only the base of the filename.
+
+	The editor posts a "screenshot" message to the cart for its menu item.
+*/
+
+// defaultCaptureDir - the editor chooses the dir captures are saved to
+func defaultCaptureDir() string {
+	return ""
+}
+
+// saveCapture - posts a screenshot, video or replay to the editor to save as filename
+func saveCapture(filename string, data []byte) error {
+	parent := js.Global.Get("parent")
+	if parent == js.Undefined || parent == js.Global {
+		return fmt.Errorf("Error saving capture - cart is not running in the editor")
+	}
+	parent.Call("postMessage", map[string]interface{}{
+		"name": "capture",
+		"payload": map[string]interface{}{
+			"name": filepath.Base(filename),
+			"data": base64.StdEncoding.EncodeToString(data),
+		},
+	}, "*")
+	return nil
+}
+
+// listenForMessages - handles messages posted to the cart by the editor
+func listenForMessages() {
+	js.Global.Call("addEventListener", "message", func(event *js.Object) {
+		data := event.Get("data")
+		if data == nil || data == js.Undefined || data.Get("name").String() != "screenshot" {
+			return
+		}
+		// event handlers must not block
+		go _console.takeScreenshot()
+	})
+}
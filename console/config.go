@@ -1,12 +1,19 @@
 package console
 
+import "fmt"
+
 type Config struct {
-	BorderWidth     int
-	ConsoleWidth    int
-	ConsoleHeight   int
-	ScreenshotScale int
-	GifScale        int
-	GifLength       int
+	BorderWidth      int
+	ConsoleWidth     int
+	ConsoleHeight    int
+	MapWidth         int // tilemap width in cells
+	MapHeight        int // tilemap height in cells
+	ScreenshotScale  int
+	ScreenshotBorder bool // include console border in screenshots
+	GifScale         int
+	GifLength        int
+	CaptureDir       string // directory for screenshots, videos & replays
+	KeyBindings      KeyBindings
 	// private vars
 	palette     *palette
 	consoleType ConsoleType
@@ -18,22 +25,47 @@ type Config struct {
 }
 
 var optVerbose bool
-var screenshotScale int
-var gifScale int
-var gifLength int
+var screenshotScale = 4
+var gifScale = 2
+var gifLength = 8 // seconds
 
 func NewConfig(consoleType ConsoleType) Config {
+	config := newPico8Config() // always default to PICO8
 	switch consoleType {
-	case PICO8:
-		return newPico8Config()
 	case TIC80:
-		return newTic80Config()
+		config = newTic80Config()
 	case ZXSPECTRUM:
-		return newZXSpectrumConfig()
+		config = newZXSpectrumConfig()
 	case CBM64:
-		return newCBM64Config()
+		config = newCBM64Config()
+	}
+	config.CaptureDir = defaultCaptureDir()
+	return config
+}
+
+// SetCaptureDir - sets the directory the running console saves screenshots, videos & replays to
+func SetCaptureDir(dir string) {
+	_console.Lock()
+	defer _console.Unlock()
+	_console.Config.CaptureDir = dir
+}
+
+// SetScreenshotScale - sets how many times larger than the console screenshots are saved
+func SetScreenshotScale(scale int) error {
+	if scale < 1 {
+		return fmt.Errorf("Error setting screenshot scale - must be at least 1: %d", scale)
 	}
-	return newPico8Config() // always default to PICO8
+	_console.Lock()
+	defer _console.Unlock()
+	_console.Config.ScreenshotScale = scale
+	return nil
+}
+
+// SetScreenshotBorder - sets whether screenshots include the console border
+func SetScreenshotBorder(border bool) {
+	_console.Lock()
+	defer _console.Unlock()
+	_console.Config.ScreenshotBorder = border
 }
 
 // Default configs for different console types
@@ -41,9 +73,12 @@ func newPico8Config() Config {
 	config := Config{
 		ConsoleWidth:    128,
 		ConsoleHeight:   128,
+		MapWidth:        128,
+		MapHeight:       64,
 		ScreenshotScale: screenshotScale,
 		GifScale:        gifScale,
 		GifLength:       gifLength,
+		KeyBindings:     newKeyBindings(PICO8),
 		consoleType:     PICO8,
 		fontWidth:       4,
 		fontHeight:      8,
@@ -58,9 +93,12 @@ func newTic80Config() Config {
 	config := Config{
 		ConsoleWidth:    240,
 		ConsoleHeight:   136,
+		MapWidth:        240,
+		MapHeight:       136,
 		ScreenshotScale: screenshotScale,
 		GifScale:        gifScale,
 		GifLength:       gifLength,
+		KeyBindings:     newKeyBindings(TIC80),
 		consoleType:     TIC80,
 		fontWidth:       8,
 		fontHeight:      8,
@@ -73,36 +111,44 @@ func newTic80Config() Config {
 
 func newZXSpectrumConfig() Config {
 	config := Config{
-		BorderWidth:     25,
-		ConsoleWidth:    256,
-		ConsoleHeight:   192,
-		ScreenshotScale: screenshotScale,
-		GifScale:        gifScale,
-		GifLength:       gifLength,
-		consoleType:     ZXSPECTRUM,
-		fontWidth:       8,
-		fontHeight:      8,
-		BgColor:         ZX_WHITE,
-		FgColor:         ZX_BLACK,
-		BorderColor:     ZX_WHITE,
+		BorderWidth:      25,
+		ConsoleWidth:     256,
+		ConsoleHeight:    192,
+		MapWidth:         128,
+		MapHeight:        64,
+		ScreenshotScale:  screenshotScale,
+		ScreenshotBorder: true,
+		GifScale:         gifScale,
+		GifLength:        gifLength,
+		KeyBindings:      newKeyBindings(ZXSPECTRUM),
+		consoleType:      ZXSPECTRUM,
+		fontWidth:        8,
+		fontHeight:       8,
+		BgColor:          ZX_WHITE,
+		FgColor:          ZX_BLACK,
+		BorderColor:      ZX_WHITE,
 	}
 	return config
 }
 
 func newCBM64Config() Config {
 	config := Config{
-		BorderWidth:     25,
-		ConsoleWidth:    320,
-		ConsoleHeight:   200,
-		ScreenshotScale: screenshotScale,
-		GifScale:        gifScale,
-		GifLength:       gifLength,
-		consoleType:     CBM64,
-		fontWidth:       8,
-		fontHeight:      8,
-		BgColor:         C64_BLUE,
-		FgColor:         C64_LIGHT_BLUE,
-		BorderColor:     C64_LIGHT_BLUE,
+		BorderWidth:      25,
+		ConsoleWidth:     320,
+		ConsoleHeight:    200,
+		MapWidth:         128,
+		MapHeight:        64,
+		ScreenshotScale:  screenshotScale,
+		ScreenshotBorder: true,
+		GifScale:         gifScale,
+		GifLength:        gifLength,
+		KeyBindings:      newKeyBindings(CBM64),
+		consoleType:      CBM64,
+		fontWidth:        8,
+		fontHeight:       8,
+		BgColor:          C64_BLUE,
+		FgColor:          C64_LIGHT_BLUE,
+		BorderColor:      C64_LIGHT_BLUE,
 	}
 	return config
 }
@@ -4,10 +4,9 @@ import (
 	"bytes"
 	"fmt"
 	"image"
-	"image/color"
 	_ "image/png"
 	"io/ioutil"
-	"log"
+	"math/rand"
 	"time"
 
 	"golang.org/x/image/font"
@@ -15,7 +14,6 @@ import (
 	"sync"
 
 	"github.com/golang/freetype/truetype"
-	"github.com/hajimehoshi/ebiten"
 	"github.com/telecoda/pico-go-electron/console/resources/fonts"
 	"github.com/telecoda/pico-go-electron/console/resources/images"
 )
@@ -25,19 +23,18 @@ import (
 var _console = &console{}
 
 const (
-	_version        = "v0.1"
-	_logoWidth      = 57
-	_logoHeight     = 24
-	_spriteWidth    = 8
-	_spriteHeight   = 8
-	_spritesPerLine = 16
-	_maxCmdLen      = 254
-	_cursorFlash    = time.Duration(500 * time.Millisecond)
+	_version      = "v0.1"
+	_logoWidth    = 57
+	_logoHeight   = 24
+	_spriteWidth  = 8
+	_spriteHeight = 8
+	_maxCmdLen    = 254
+	_cursorFlash  = time.Duration(500 * time.Millisecond)
+	_fps          = 60
 )
 
 const (
 	userSpriteBank1 = 0
-	userSpriteMask1 = 1
 )
 
 var lastFrame time.Time
@@ -47,8 +44,10 @@ var endFrame time.Time
 type console struct {
 	sync.Mutex
 	Config
+	window // window & audio device, only in builds with a window
 
-	showFPS bool
+	showFPS  bool
+	headless bool // no window or audio, frames are driven by RunHeadless
 
 	// files
 	baseDir    string
@@ -57,41 +56,69 @@ type console struct {
 	consoleType ConsoleType
 	cart        Cartridge
 
-	screen *ebiten.Image
 	pImage *image.Paletted
 	pb     *pixelBuffer
 
 	font              font.Face
 	sprites           []*image.Paletted
-	currentSpriteBank int
+	currentSpriteBank int       // index of sprite sheet in sprites used for drawing
+	spriteFlags       [][]uint8 // 8 flag bits per sprite number of each bank
+
+	tilemap []uint8 // sprite number per map cell
+
+	ram [MEM_SIZE]uint8 // memory not backed by other console state
 
 	originalPalette *palette
 
+	buttons    *buttonState
+	mouse      mouseState
+	input      InputFunc // scripted input, nil for live input
+	frameCount int       // frames run since cart was loaded
+	recording  *Replay   // seed & input recorded since cart was loaded
+	playback   *Replay   // replay fed to the next cart loaded
+	replay     *Replay   // replay feeding input to the running cart
+	replayEnd  int       // frame live input is restored after the replay
+
+	rng *rand.Rand // console random numbers, seeded when cart is loaded
+
+	synth *synth
+
 	//state    Persister
-	//recorder Recorder
-	//Inputter
+	recorder *recorder
 }
 
-func Run(cart Cartridge) error {
+// RunHeadless - runs cart for a number of frames without a window or audio device
+func RunHeadless(cart Cartridge, frames int) error {
+	if !_console.headless {
+		return fmt.Errorf("Error running headless - console not initialised with InitHeadless")
+	}
 
-	// load cartridge
-	// run main loop
+	if err := _console.loadCart(cart); err != nil {
+		return err
+	}
 
-	// TODO screen recorder
-	//	_console.recorder = NewRecorder(cfg.FPS, cfg.GifLength)
-	//	_console.Inputter = NewInputter()
+	for i := 0; i < frames; i++ {
+		if err := _console.frame(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadCart - initialise font and cart ready for the first frame
+func (c *console) loadCart(cart Cartridge) error {
 
 	// init font
 	f := bytes.NewReader(fonts.Font_ttf)
 
 	b, err := ioutil.ReadAll(f)
 	if err != nil {
-		log.Fatal(err)
+		return fmt.Errorf("Error loading font: %s", err)
 	}
 
 	tt, err := truetype.Parse(b)
 	if err != nil {
-		log.Fatal(err)
+		return fmt.Errorf("Error parsing font: %s", err)
 	}
 
 	const dpi = 48
@@ -101,21 +128,38 @@ func Run(cart Cartridge) error {
 		Hinting: font.HintingFull,
 	})
 
-	_console.font = mplusNormalFont
+	c.font = mplusNormalFont
 
-	_console.cart = cart
+	c.cart = cart
+	c.frameCount = 0
+
+	// replays reuse the recorded seed so random numbers repeat
+	c.Lock()
+	seed := time.Now().UnixNano()
+	// a replay still playing stops, a pending replay feeds input from now on
+	if c.replay != nil {
+		c.replay = nil
+		c.input = nil
+	}
+	if c.playback != nil {
+		seed = c.playback.Seed
+		c.replay = c.playback
+		c.replayEnd = c.playback.Len()
+		c.input = c.playback.Input
+		c.playback = nil
+	}
+	c.rng = rand.New(rand.NewSource(seed))
+	c.recording = &Replay{Seed: seed}
+	c.Unlock()
 
 	// set reference to pixel buffer
-	cart.initPb(_console.pb)
+	cart.initPb(c.pb)
 
 	// init the cart
-	_console.cart.Init()
-
-	// poll events
-	endFrame = time.Now() // init end frame
-	startFrame = time.Now()
-
-	return ebiten.Run(_console.update, _console.Config.ConsoleWidth, _console.Config.ConsoleHeight, 1, "pico-go")
+	if err := c.cart.Init(); err != nil {
+		return fmt.Errorf("Error initialising cart: %s", err)
+	}
+	return nil
 }
 
 func ShowFPS() {
@@ -130,7 +174,23 @@ func HideFPS() {
 	_console.showFPS = false
 }
 
+// GetFrame - returns the paletted image the console draws frames into
+func GetFrame() *image.Paletted {
+	return _console.pb.GetFrame()
+}
+
+// Init - initialise console of type, ready to run a cart
 func Init(consoleType ConsoleType) error {
+	return initConsole(consoleType, false)
+}
+
+// InitHeadless - initialise console of type without a window or audio device,
+// frames are only drawn into the paletted image returned by GetFrame
+func InitHeadless(consoleType ConsoleType) error {
+	return initConsole(consoleType, true)
+}
+
+func initConsole(consoleType ConsoleType, headless bool) error {
 
 	// validate type
 	if _, ok := ConsoleTypes[consoleType]; !ok {
@@ -140,12 +200,20 @@ func Init(consoleType ConsoleType) error {
 	cfg := NewConfig(consoleType)
 
 	_console.Config = cfg
+	_console.headless = headless
 
-	// init sprites
-	// There are 2 sprite banks
-	// 0 = User sprite bank 1
-	// 1 = User sprite bank 1 mask
-	_console.sprites = make([]*image.Paletted, 2)
+	// init audio
+	_console.synth = newSynth()
+
+	// init input
+	_console.buttons = newButtonState(cfg.KeyBindings)
+
+	// init screen recorder
+	_console.recorder = newRecorder(_fps, cfg.GifLength, cfg.ConsoleWidth, cfg.ConsoleHeight)
+
+	// init tilemap
+	_console.tilemap = make([]uint8, cfg.MapWidth*cfg.MapHeight)
+	_console.ram = [MEM_SIZE]uint8{}
 
 	_console.palette = newPalette(cfg.consoleType)
 	_console.originalPalette = newPalette(cfg.consoleType)
@@ -156,30 +224,16 @@ func Init(consoleType ConsoleType) error {
 	_console.pImage = pImage
 
 	// init sprites
+	// 0 = User sprite bank 1
+	// further banks are added by LoadSprites
 	sprites, _, err := image.Decode(bytes.NewReader(images.Sprites_png))
 	if err != nil {
 		return fmt.Errorf("Error loading sprites: %s", err)
 	}
-	_console.sprites[userSpriteBank1] = sprites.(*image.Paletted)
-
-	_console.sprites[userSpriteBank1].Palette = _console.palette.colors
-
-	// create a mask
-	masks, _, err := image.Decode(bytes.NewReader(images.Sprites_png))
-	if err != nil {
-		return fmt.Errorf("Error loading sprites: %s", err)
-	}
-	_console.sprites[userSpriteMask1] = masks.(*image.Paletted)
-
-	// convert all black pixels to zero alpha
-	mask := _console.sprites[userSpriteMask1]
-	// set palette on mask
-	maskPalette := newPalette(PICO8)
-	maskPalette.colors[0] = color.RGBA{R: 0, G: 0, B: 0, A: 0}
-	for i := 1; i < 16; i++ {
-		maskPalette.colors[i] = color.RGBA{R: 255, G: 255, B: 255, A: 255}
-	}
-	mask.Palette = maskPalette.colors
+	_console.sprites = nil
+	_console.spriteFlags = nil
+	_console.currentSpriteBank = userSpriteBank1
+	addSpriteBank(sprites.(*image.Paletted))
 
 	// init pixelbuffer
 	pb, err := newPixelBuffer(_console.Config)
@@ -188,76 +242,63 @@ func Init(consoleType ConsoleType) error {
 	}
 
 	_console.pb = pb
+	pb.PalReset()
 
 	return nil
 }
 
-func (c *console) update(screen *ebiten.Image) error {
-	c.screen = screen
-	if ebiten.IsRunningSlowly() {
-		return nil
-	}
-
-	c.cart.Update()
-	c.cart.Render()
-
-	// record frame
-	//			c.recorder.AddFrame(mode.GetFrame(), mode)
-
-	// convert paletted image to RGBA
-
-	pb := _console.pb
-
-	pb.flipReady = true
-
-	return pb.Flip()
+// skipFrame - advance music by a dropped frame so it keeps time with the audio device
+func (c *console) skipFrame() {
+	c.synth.updateMusic(_sampleRate / _fps)
 }
 
-func (c *console) handleInput() error {
-
-	// This method is called every iteration
-	// it checks system wide input events such as the escape key
-
-	// 	// TODO keys to implement
-	// 	// F7 Capture cartridge label image
-	// 	// F8 Start recording a video
-	// 	// F9 Save GIF video to desktop (max: 8 seconds by default)
-
-	// 		case sdl.K_F6:
-	// 			if err := c.saveScreenshot(); err != nil {
-	// 				return err
-	// 			}
-	// 		case sdl.K_F9:
-	// 			if err := c.saveVideo(); err != nil {
-	// 				return err
-	// 			}
-	// 		default:
-	// 			// pass keydown events to mode handle
-	// 			if err := mode.HandleEvent(event); err != nil {
-	// 				return err
-	// 			}
-	// 		}
-
-	//	}
+// frame - advance music and cart by one frame and flip the pixel buffer
+func (c *console) frame() error {
 
-	return nil
-}
+	// scripted input replaces live input, live input returns once a replay ends
+	c.Lock()
+	ended := c.replay != nil && c.frameCount >= c.replayEnd
+	if ended {
+		c.replay = nil
+		c.input = nil
+	}
+	input := c.input
+	c.Unlock()
+	if ended {
+		c.setInput(InputState{})
+	}
+	if input != nil {
+		c.setInput(input(c.frameCount))
+	}
+	c.frameCount++
 
-func (c *console) mouseClicked(x, y int32) {
-	// transform window x,y coords to pixel buffer coords
-	fmt.Printf("Mouse clicked at x: %d y: %d\n", x, y)
-}
+	// record input so this run can be replayed
+	c.Lock()
+	if c.frameCount <= _maxReplayFrames {
+		c.recording.Append(c.inputState())
+	}
+	c.Unlock()
 
-// saveScreenshot - saves a screenshot of current frame
-func (c *console) saveScreenshot() error {
+	// advance music sequencer by one frame
+	c.synth.updateMusic(_sampleRate / _fps)
 
-	// return c.recorder.SaveScreenshot("out.png", c.Config.ScreenshotScale)
+	c.cart.Update()
+	c.cart.Render()
 
-	return nil
+	c.pb.flipReady = true
+
+	return c.pb.Flip()
 }
 
 // saveVideo - saves a video of last x seconds
-func (c *console) saveVideo() error {
-	// return c.recorder.SaveVideo("out.gif", c.Config.GifScale)
-	return nil
+func (c *console) saveVideo() (string, error) {
+	data, err := c.recorder.Video(c.Config.GifScale)
+	if err != nil {
+		return "", err
+	}
+	filename := c.captureFilename(".gif")
+	if err := saveCapture(filename, data); err != nil {
+		return "", err
+	}
+	return filename, nil
 }
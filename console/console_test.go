@@ -0,0 +1,60 @@
+package console
+
+import (
+	"testing"
+)
+
+type countingCart struct {
+	*BaseCartridge
+	inits   int
+	updates int
+	renders int
+}
+
+func (c *countingCart) Init() error {
+	c.inits++
+	return nil
+}
+
+func (c *countingCart) Update() {
+	c.updates++
+}
+
+func (c *countingCart) Render() {
+	c.Cls(0)
+	c.PSet(c.updates, 0, 8)
+	c.renders++
+}
+
+func TestRunHeadless(t *testing.T) {
+	if err := InitHeadless(PICO8); err != nil {
+		t.Fatalf("Failed to init headless console: %s", err)
+	}
+
+	cart := &countingCart{BaseCartridge: NewBaseCart()}
+	if err := RunHeadless(cart, 10); err != nil {
+		t.Fatalf("Failed to run headless: %s", err)
+	}
+
+	if cart.inits != 1 || cart.updates != 10 || cart.renders != 10 {
+		t.Errorf("Expected 1 init, 10 updates & 10 renders but got %d, %d & %d", cart.inits, cart.updates, cart.renders)
+	}
+
+	frame := _console.pb.GetFrame()
+	if frame.ColorIndexAt(10, 0) != 8 {
+		t.Errorf("Expected pixel at 10,0 to be color 8 but got %d", frame.ColorIndexAt(10, 0))
+	}
+	if frame.ColorIndexAt(9, 0) != 0 {
+		t.Errorf("Expected pixel at 9,0 to be cleared but got %d", frame.ColorIndexAt(9, 0))
+	}
+}
+
+func TestRunHeadlessNotInitialised(t *testing.T) {
+	if err := Init(PICO8); err != nil {
+		t.Fatalf("Failed to init console: %s", err)
+	}
+	cart := &countingCart{BaseCartridge: NewBaseCart()}
+	if err := RunHeadless(cart, 1); err == nil {
+		t.Errorf("Expected error running headless on a windowed console")
+	}
+}
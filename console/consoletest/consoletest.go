@@ -0,0 +1,176 @@
+// Package consoletest runs carts headless and compares their frames
+// against golden PNG images stored in testdata.
+//
+// Built with the headless tag the golden tests don't link ebiten, so they
+// need no display, audio device or cgo and can run in CI:
+//
+//	CGO_ENABLED=0 go test -tags headless ./console/consoletest
+//
+// Run tests with -update to regenerate the golden images.
+package consoletest
+
+import (
+	"flag"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/telecoda/pico-go-electron/console"
+)
+
+var update = flag.Bool("update", false, "update golden images")
+
+// Script - input states keyed by frame number, each state is held until the next
+type Script map[int]console.InputState
+
+// Test - a cart to run headless and the golden image to compare its last frame with
+type Test struct {
+	ConsoleType console.ConsoleType
+	Cart        console.Cartridge
+	Frames      int
+	Input       Script
+	Replay      string // optional replay file in testdata, replaces Input & defaults Frames
+	Golden      string // filename of golden image in testdata
+}
+
+// Buttons - returns button bits for ids, for use in InputState.Buttons
+func Buttons(ids ...int) uint8 {
+	var bits uint8
+	for _, id := range ids {
+		bits |= 1 << uint(id)
+	}
+	return bits
+}
+
+// input - returns the most recent scripted input state for frame
+func (s Script) input(frame int) console.InputState {
+	for f := frame; f >= 0; f-- {
+		if in, ok := s[f]; ok {
+			return in
+		}
+	}
+	return console.InputState{}
+}
+
+// Run - runs test cart headless and compares the final frame with its golden image
+func Run(t *testing.T, test Test) {
+	t.Helper()
+
+	consoleType := test.ConsoleType
+	if consoleType == "" {
+		consoleType = console.PICO8
+	}
+	if err := console.InitHeadless(consoleType); err != nil {
+		t.Fatalf("Failed to init console: %s", err)
+	}
+
+	frames := test.Frames
+	if test.Replay != "" {
+		replay, err := console.LoadReplay(filepath.Join("testdata", test.Replay))
+		if err != nil {
+			t.Fatalf("Failed to load replay: %s", err)
+		}
+		console.PlayReplay(replay)
+		if frames == 0 {
+			frames = replay.Len()
+		}
+	} else {
+		console.SetInput(test.Input.input)
+	}
+	defer console.PlayReplay(nil)
+
+	if frames < 1 {
+		frames = 1
+	}
+	if err := console.RunHeadless(test.Cart, frames); err != nil {
+		t.Fatalf("Failed to run cart: %s", err)
+	}
+
+	CompareGolden(t, console.GetFrame(), test.Golden)
+}
+
+// CompareGolden - compares frame with golden image, writing a diff image on mismatch
+func CompareGolden(t *testing.T, frame image.Image, golden string) {
+	t.Helper()
+
+	filename := filepath.Join("testdata", golden)
+	if *update {
+		if err := savePNG(filename, frame); err != nil {
+			t.Fatalf("Failed to update golden image: %s", err)
+		}
+		return
+	}
+
+	expected, err := loadPNG(filename)
+	if err != nil {
+		t.Fatalf("Failed to load golden image: %s - run with -update to create it", err)
+	}
+
+	diff, count := diffImages(expected, frame)
+	if count == 0 {
+		return
+	}
+
+	diffFilename := strings.TrimSuffix(filename, filepath.Ext(filename)) + ".diff.png"
+	if err := savePNG(diffFilename, diff); err != nil {
+		t.Errorf("Failed to save diff image: %s", err)
+	}
+	t.Errorf("Frame does not match %s - %d pixels differ, see %s", filename, count, diffFilename)
+}
+
+// diffImages - returns an image highlighting differing pixels in red and a count of them
+func diffImages(expected, actual image.Image) (*image.RGBA, int) {
+	bounds := actual.Bounds().Union(expected.Bounds())
+	diff := image.NewRGBA(bounds)
+	count := 0
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			p := image.Pt(x, y)
+			if !p.In(expected.Bounds()) || !p.In(actual.Bounds()) {
+				diff.Set(x, y, color.RGBA{R: 255, A: 255})
+				count++
+				continue
+			}
+			e := color.RGBAModel.Convert(expected.At(x, y))
+			a := color.RGBAModel.Convert(actual.At(x, y))
+			if e != a {
+				diff.Set(x, y, color.RGBA{R: 255, A: 255})
+				count++
+				continue
+			}
+			// matching pixels are faded so differences stand out
+			g := color.GrayModel.Convert(a).(color.Gray)
+			diff.Set(x, y, color.RGBA{R: g.Y / 4, G: g.Y / 4, B: g.Y / 4, A: 255})
+		}
+	}
+	return diff, count
+}
+
+func loadPNG(filename string) (image.Image, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return png.Decode(f)
+}
+
+func savePNG(filename string, img image.Image) error {
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		return err
+	}
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	if err := png.Encode(f, img); err != nil {
+		f.Close()
+		return fmt.Errorf("Error encoding %s: %s", filename, err)
+	}
+	return f.Close()
+}
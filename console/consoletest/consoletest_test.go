@@ -0,0 +1,200 @@
+package consoletest
+
+import (
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/telecoda/pico-go-electron/console"
+)
+
+// drawCart - cart that calls render each frame, optionally moving with player 0 input
+type drawCart struct {
+	*console.BaseCartridge
+	x, y   int
+	render func(c *drawCart)
+}
+
+func newDrawCart(render func(c *drawCart)) *drawCart {
+	return &drawCart{
+		BaseCartridge: console.NewBaseCart(),
+		render:        render,
+	}
+}
+
+func (c *drawCart) Init() error {
+	return nil
+}
+
+func (c *drawCart) Update() {
+	if c.Btn(console.BTN_LEFT) {
+		c.x--
+	}
+	if c.Btn(console.BTN_RIGHT) {
+		c.x++
+	}
+	if c.Btn(console.BTN_UP) {
+		c.y--
+	}
+	if c.Btn(console.BTN_DOWN) {
+		c.y++
+	}
+}
+
+func (c *drawCart) Render() {
+	c.Cls(console.PICO8_BLACK)
+	c.render(c)
+}
+
+func TestPrimitives(t *testing.T) {
+	tests := []Test{
+		{
+			Golden: "circles.png",
+			Cart: newDrawCart(func(c *drawCart) {
+				c.Circle(32, 32, 20, console.PICO8_RED)
+				c.CircleFill(96, 32, 20, console.PICO8_GREEN)
+				c.Circle(32, 96, 1, console.PICO8_WHITE)
+				c.CircleFill(96, 96, 0, console.PICO8_YELLOW)
+			}),
+		},
+		{
+			Golden: "lines.png",
+			Cart: newDrawCart(func(c *drawCart) {
+				c.Line(0, 0, 127, 127, console.PICO8_WHITE)
+				c.Line(127, 0, 0, 127, console.PICO8_PINK)
+				c.Line(10, 64, 117, 70, console.PICO8_BLUE)
+				c.Line(64, 10, 70, 117, console.PICO8_ORANGE)
+			}),
+		},
+		{
+			Golden: "rects.png",
+			Cart: newDrawCart(func(c *drawCart) {
+				c.Rect(4, 4, 60, 60, console.PICO8_PEACH)
+				c.RectFill(68, 4, 124, 60, console.PICO8_DARK_BLUE)
+				c.Camera(-10, -70)
+				c.Clip(0, 64, 64, 64)
+				c.RectFill(0, 0, 100, 100, console.PICO8_DARK_GREEN)
+				c.Camera(0, 0)
+				c.Clip(0, 0, 128, 128)
+			}),
+		},
+		{
+			Golden: "fillp.png",
+			Cart: newDrawCart(func(c *drawCart) {
+				c.Fillp(0x5a5a, false, console.PICO8_DARK_BLUE)
+				c.RectFill(4, 4, 60, 60, console.PICO8_BLUE)
+				c.Fillp(0x0f0f, true)
+				c.CircleFill(96, 32, 24, console.PICO8_ORANGE)
+				c.Fillp(0x8421, false, console.PICO8_WHITE)
+				c.Rect(4, 68, 60, 124, console.PICO8_RED)
+				c.Circle(96, 96, 24, console.PICO8_GREEN)
+				c.Line(0, 127, 127, 64, console.PICO8_YELLOW)
+				c.Fillp(0, false)
+			}),
+		},
+		{
+			Golden: "polygons.png",
+			Cart: newDrawCart(func(c *drawCart) {
+				c.TriFill(8, 8, 56, 20, 20, 56, console.PICO8_RED)
+				c.Tri(72, 8, 120, 20, 84, 56, console.PICO8_PINK)
+				star := []image.Point{{X: 32, Y: 66}, {X: 46, Y: 120}, {X: 4, Y: 86}, {X: 60, Y: 86}, {X: 18, Y: 120}}
+				c.PolyFill(star, console.PICO8_YELLOW)
+				c.Poly(star, console.PICO8_ORANGE)
+				// floor of map cells narrowing towards the top
+				for x := 0; x < 4; x++ {
+					c.MSet(x, 0, 1)
+				}
+				for i := 0; i < 56; i++ {
+					step := 4.0 / 56 * 32 / float64(i+8)
+					c.Tline(68, 68+i, 124, 68+i, 2-step*28, float64(i)/56, step, 0)
+				}
+			}),
+		},
+		{
+			Golden: "ovals.png",
+			Cart: newDrawCart(func(c *drawCart) {
+				c.Oval(4, 12, 60, 52, console.PICO8_BLUE)
+				c.OvalFill(68, 4, 91, 60, console.PICO8_GREEN)
+				c.OvalFill(96, 24, 124, 37, console.PICO8_INDIGO)
+				c.RoundRect(4, 68, 60, 124, 12, console.PICO8_PEACH)
+				c.RoundRectFill(68, 68, 124, 100, 8, console.PICO8_BROWN)
+				c.RoundRectFill(68, 108, 124, 123, 20, console.PICO8_DARK_PURPLE)
+			}),
+		},
+		{
+			Golden: "sprites.png",
+			Cart: newDrawCart(func(c *drawCart) {
+				c.Sprite(1, 0, 0, 8, 8, 8, 8)
+				c.Sprite(1, 16, 0, 8, 8, 32, 32)
+				c.SpriteFlipped(1, 0, 48, 8, 8, 16, 16, true, false)
+				c.SpriteFlipped(1, 32, 48, 8, 8, 16, 16, false, true)
+				c.SpriteRotated(1, 64, 64, 8, 8, 32, 32, 90)
+			}),
+		},
+		{
+			Golden: "print.png",
+			Cart: newDrawCart(func(c *drawCart) {
+				c.PrintAt("HELLO PICO-GO", 8, 8, console.PICO8_WHITE)
+				c.PrintAt("0123456789", 8, 24, console.PICO8_LIGHT_GRAY)
+			}),
+		},
+	}
+
+	for _, test := range tests {
+		Run(t, test)
+	}
+}
+
+func TestScriptedInput(t *testing.T) {
+	// hold right for 10 frames then down for 5
+	Run(t, Test{
+		Golden: "input.png",
+		Frames: 20,
+		Input: Script{
+			0:  {Buttons: [2]uint8{Buttons(console.BTN_RIGHT)}},
+			10: {Buttons: [2]uint8{Buttons(console.BTN_DOWN)}},
+			15: {},
+		},
+		Cart: newDrawCart(func(c *drawCart) {
+			c.RectFill(c.x*4, c.y*4, c.x*4+3, c.y*4+3, console.PICO8_RED)
+		}),
+	})
+}
+
+func TestScriptInput(t *testing.T) {
+	s := Script{
+		2: {MouseX: 2},
+		5: {MouseX: 5},
+	}
+	expected := []int{0, 0, 2, 2, 2, 5, 5}
+	for frame, x := range expected {
+		if in := s.input(frame); in.MouseX != x {
+			t.Errorf("Frame %d: expected mouse x %d but got %d", frame, x, in.MouseX)
+		}
+	}
+}
+
+func TestDiffImages(t *testing.T) {
+	expected := image.NewRGBA(image.Rect(0, 0, 4, 4))
+	actual := image.NewRGBA(image.Rect(0, 0, 4, 4))
+	actual.Set(1, 2, color.RGBA{G: 255, A: 255})
+
+	diff, count := diffImages(expected, actual)
+	if count != 1 {
+		t.Fatalf("Expected 1 differing pixel but got %d", count)
+	}
+	if diff.At(1, 2) != (color.RGBA{R: 255, A: 255}) {
+		t.Errorf("Expected differing pixel to be red but got %v", diff.At(1, 2))
+	}
+}
+
+func TestReplay(t *testing.T) {
+	// replay of the scripted input test should render the same frame
+	Run(t, Test{
+		Golden: "input.png",
+		Replay: "input.pgr",
+		Cart: newDrawCart(func(c *drawCart) {
+			c.RectFill(c.x*4, c.y*4, c.x*4+3, c.y*4+3, console.PICO8_RED)
+		}),
+	})
+}
@@ -0,0 +1,181 @@
+package console
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Fix - 16.16 fixed point number matching pico8 number arithmetic,
+// results wrap around on overflow just like pico8
+type Fix int32
+
+const (
+	_fixOne = 1 << 16
+	_fixMax = Fix(math.MaxInt32)  // 0x7fff.ffff
+	_fixMin = Fix(-math.MaxInt32) // 0x8000.0001, returned by division by zero
+)
+
+// FixFromFloat - converts f to the nearest fixed point value
+func FixFromFloat(f float64) Fix {
+	return Fix(int32(int64(math.Floor(f*_fixOne + 0.5))))
+}
+
+// FixFromInt - converts i to fixed point, keeping the low 16 bits of i
+func FixFromInt(i int) Fix {
+	return Fix(int32(i << 16))
+}
+
+// Float - converts to float64, this is always exact
+func (f Fix) Float() float64 {
+	return float64(f) / _fixOne
+}
+
+// Int - integer part, rounded down like pico8 flr
+func (f Fix) Int() int {
+	return int(f >> 16)
+}
+
+// Add - f + g
+func (f Fix) Add(g Fix) Fix {
+	return f + g
+}
+
+// Sub - f - g
+func (f Fix) Sub(g Fix) Fix {
+	return f - g
+}
+
+// Mul - f * g
+func (f Fix) Mul(g Fix) Fix {
+	return Fix(int32((int64(f) * int64(g)) >> 16))
+}
+
+// Div - f / g, division by zero or overflow returns the largest value of the same sign
+func (f Fix) Div(g Fix) Fix {
+	if g == 0 {
+		if f < 0 {
+			return _fixMin
+		}
+		return _fixMax
+	}
+	q := (int64(f) << 16) / int64(g)
+	if q > int64(_fixMax) {
+		return _fixMax
+	}
+	if q < int64(_fixMin) {
+		return _fixMin
+	}
+	return Fix(q)
+}
+
+// Mod - f % g, the result is always positive like pico8, modulo zero returns 0
+func (f Fix) Mod(g Fix) Fix {
+	if g == 0 {
+		return 0
+	}
+	if g < 0 {
+		g = -g
+	}
+	r := f % g
+	if r < 0 {
+		r += g
+	}
+	return r
+}
+
+// Neg - -f
+func (f Fix) Neg() Fix {
+	return -f
+}
+
+// Abs - absolute value of f
+func (f Fix) Abs() Fix {
+	if f < 0 {
+		return -f
+	}
+	return f
+}
+
+// Band - bitwise and
+func (f Fix) Band(g Fix) Fix {
+	return f & g
+}
+
+// Bor - bitwise or
+func (f Fix) Bor(g Fix) Fix {
+	return f | g
+}
+
+// Bxor - bitwise exclusive or
+func (f Fix) Bxor(g Fix) Fix {
+	return f ^ g
+}
+
+// Bnot - bitwise not
+func (f Fix) Bnot() Fix {
+	return ^f
+}
+
+// Shl - shift left n bits, negative n shifts right
+func (f Fix) Shl(n int) Fix {
+	if n < 0 {
+		return f.Shr(-n)
+	}
+	if n >= 32 {
+		return 0
+	}
+	return Fix(uint32(f) << uint(n))
+}
+
+// Shr - arithmetic shift right n bits keeping the sign, negative n shifts left
+func (f Fix) Shr(n int) Fix {
+	if n < 0 {
+		return f.Shl(-n)
+	}
+	if n >= 32 {
+		n = 31
+	}
+	return f >> uint(n)
+}
+
+// Lshr - logical shift right n bits filling with zeros, negative n shifts left
+func (f Fix) Lshr(n int) Fix {
+	if n < 0 {
+		return f.Shl(-n)
+	}
+	if n >= 32 {
+		return 0
+	}
+	return Fix(uint32(f) >> uint(n))
+}
+
+// Rotl - rotate bits left by n
+func (f Fix) Rotl(n int) Fix {
+	s := uint(n & 31)
+	u := uint32(f)
+	return Fix(u<<s | u>>(32-s))
+}
+
+// Rotr - rotate bits right by n
+func (f Fix) Rotr(n int) Fix {
+	return f.Rotl(-n)
+}
+
+// String - formats like pico8 tostr, up to 4 decimal places without trailing zeros
+func (f Fix) String() string {
+	s := strconv.FormatFloat(f.Float(), 'f', 4, 64)
+	s = strings.TrimRight(s, "0")
+	s = strings.TrimSuffix(s, ".")
+	if s == "-0" {
+		return "0"
+	}
+	return s
+}
+
+// Hex - formats like pico8 tostr(f, true) eg. 0x0001.8000
+func (f Fix) Hex() string {
+	u := uint32(f)
+	return fmt.Sprintf("0x%04x.%04x", u>>16, u&0xffff)
+}
@@ -0,0 +1,104 @@
+package console
+
+import (
+	"testing"
+)
+
+func TestFixConversion(t *testing.T) {
+	if f := FixFromFloat(1.5); f != 0x18000 || f.Float() != 1.5 {
+		t.Errorf("FixFromFloat(1.5): expected 0x18000 got %#x", int32(f))
+	}
+	if f := FixFromFloat(-0.5); f.Float() != -0.5 || f.Int() != -1 {
+		t.Errorf("FixFromFloat(-0.5): expected -0.5 & flr -1 got %v & %d", f.Float(), f.Int())
+	}
+	// integers wrap to 16 bits
+	if f := FixFromInt(32768); f.Int() != -32768 {
+		t.Errorf("FixFromInt(32768): expected wrap to -32768 got %d", f.Int())
+	}
+}
+
+func TestFixArithmetic(t *testing.T) {
+	type fixTest struct {
+		name     string
+		result   Fix
+		expected float64
+	}
+	one := FixFromInt(1)
+	three := FixFromInt(3)
+	max := Fix(0x7fffffff)
+	tests := []fixTest{
+		{name: "add", result: one.Add(FixFromFloat(0.25)), expected: 1.25},
+		{name: "add wraps", result: FixFromInt(32767).Add(one), expected: -32768},
+		{name: "sub", result: one.Sub(three), expected: -2},
+		{name: "mul", result: FixFromFloat(1.5).Mul(FixFromFloat(-2.5)), expected: -3.75},
+		{name: "mul wraps", result: FixFromInt(256).Mul(FixFromInt(256)), expected: 0},
+		{name: "div", result: FixFromInt(-7).Div(FixFromInt(2)), expected: -3.5},
+		{name: "div by zero", result: one.Div(0), expected: max.Float()},
+		{name: "neg div by zero", result: one.Neg().Div(0), expected: -max.Float()},
+		{name: "mod", result: FixFromInt(7).Mod(three), expected: 1},
+		{name: "mod negative", result: FixFromInt(-1).Mod(three), expected: 2},
+		{name: "mod negative divisor", result: FixFromInt(-1).Mod(three.Neg()), expected: 2},
+		{name: "mod zero", result: three.Mod(0), expected: 0},
+		{name: "abs", result: FixFromFloat(-2.5).Abs(), expected: 2.5},
+	}
+	for _, test := range tests {
+		if test.result.Float() != test.expected {
+			t.Errorf("%s: expected %v got %v", test.name, test.expected, test.result.Float())
+		}
+	}
+}
+
+func TestFixBitwise(t *testing.T) {
+	type fixTest struct {
+		name     string
+		result   Fix
+		expected Fix
+	}
+	tests := []fixTest{
+		{name: "band", result: Fix(0x00030000).Band(0x00018000), expected: 0x00010000},
+		{name: "bor", result: Fix(0x00020000).Bor(0x00008000), expected: 0x00028000},
+		{name: "bxor", result: Fix(0x00030000).Bxor(0x00010000), expected: 0x00020000},
+		{name: "bnot", result: Fix(0).Bnot(), expected: -1},
+		{name: "shl", result: FixFromInt(1).Shl(4), expected: FixFromInt(16)},
+		{name: "shl negative", result: FixFromInt(16).Shl(-4), expected: FixFromInt(1)},
+		{name: "shl 32", result: FixFromInt(1).Shl(32), expected: 0},
+		{name: "shr keeps sign", result: FixFromInt(-16).Shr(2), expected: FixFromInt(-4)},
+		{name: "shr 32", result: FixFromInt(-16).Shr(40), expected: -1},
+		{name: "lshr", result: FixFromInt(-1).Lshr(16), expected: 0x0000ffff},
+		{name: "rotl", result: Fix(-0x80000000).Rotl(1), expected: 1},
+		{name: "rotl 0", result: Fix(0x12345678).Rotl(32), expected: 0x12345678},
+		{name: "rotr", result: Fix(1).Rotr(1), expected: -0x80000000},
+	}
+	for _, test := range tests {
+		if test.result != test.expected {
+			t.Errorf("%s: expected %#x got %#x", test.name, uint32(test.expected), uint32(test.result))
+		}
+	}
+}
+
+func TestFixString(t *testing.T) {
+	type fixTest struct {
+		value    Fix
+		expected string
+	}
+	tests := []fixTest{
+		{value: FixFromInt(0), expected: "0"},
+		{value: FixFromInt(10), expected: "10"},
+		{value: FixFromFloat(-1.5), expected: "-1.5"},
+		{value: FixFromInt(1).Div(FixFromInt(3)), expected: "0.3333"},
+		{value: FixFromInt(2).Div(FixFromInt(3)), expected: "0.6667"},
+		{value: Fix(-1), expected: "0"},
+		{value: FixFromInt(-32768), expected: "-32768"},
+	}
+	for _, test := range tests {
+		if s := test.value.String(); s != test.expected {
+			t.Errorf("String(%#x): expected %s got %s", uint32(test.value), test.expected, s)
+		}
+	}
+	if h := FixFromFloat(1.5).Hex(); h != "0x0001.8000" {
+		t.Errorf("Hex: expected 0x0001.8000 got %s", h)
+	}
+	if h := FixFromInt(-1).Hex(); h != "0xffff.0000" {
+		t.Errorf("Hex: expected 0xffff.0000 got %s", h)
+	}
+}
@@ -0,0 +1,20 @@
+//go:build headless && !js
+// +build headless,!js
+
+package console
+
+import (
+	"fmt"
+)
+
+// window - builds with the headless tag have no window or audio device,
+// they only link the headless console so tests run without a display or cgo
+type window struct{}
+
+// Run - carts need a window, build without the headless tag
+func Run(cart Cartridge) error {
+	return fmt.Errorf("Error running cart - no window in headless build, use RunHeadless or build without the headless tag")
+}
+
+// display - frames stay in the paletted image without a window
+func (p *pixelBuffer) display() {}
@@ -0,0 +1,33 @@
+package console
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestHeadlessBuildWithoutEbiten(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping listing dependencies in short mode")
+	}
+	gobin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go tool not found")
+	}
+
+	// listing needs the console's module & dependencies, skip without them
+	list := exec.Command(gobin, "list", "-deps", "-tags", "headless", ".")
+	list.Env = append(os.Environ(), "CGO_ENABLED=0")
+	out, err := list.CombinedOutput()
+	if err != nil {
+		t.Skipf("Unable to list headless console dependencies: %s\n%s", err, out)
+	}
+
+	// with the headless tag the console builds without ebiten or cgo
+	for _, pkg := range strings.Fields(string(out)) {
+		if strings.HasPrefix(pkg, "github.com/hajimehoshi/ebiten") {
+			t.Errorf("Expected headless console not to depend on: %s", pkg)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package console
+
+// Button IDs - these match the pico8 button numbers
+const (
+	BTN_LEFT = iota
+	BTN_RIGHT
+	BTN_UP
+	BTN_DOWN
+	BTN_O
+	BTN_X
+)
+
+const (
+	totalButtons = 6
+	totalPlayers = 2
+)
+
+// Btnp repeat timing in frames, pico8 uses 15 & 4 frames at 30fps
+// the console runs at 60fps so these are doubled
+const (
+	btnpDelay  = 30 // frames held before repeating
+	btnpRepeat = 8  // frames between repeats
+)
+
+// buttonState - state of all buttons sampled once per frame
+type buttonState struct {
+	keys KeyBindings
+	down [totalPlayers][totalButtons]bool
+	held [totalPlayers][totalButtons]int // frames each button has been held
+}
+
+func newButtonState(keys KeyBindings) *buttonState {
+	return &buttonState{
+		keys: keys,
+	}
+}
+
+// sample - reads state of all buttons, called once per frame
+func (b *buttonState) sample(isKeyPressed func(key Key) bool) {
+	for player := range b.keys {
+		for id, keys := range b.keys[player] {
+			down := false
+			for _, key := range keys {
+				if isKeyPressed(key) {
+					down = true
+					break
+				}
+			}
+			b.setDown(id, player, down)
+		}
+	}
+}
+
+// setDown - stores button state, counting the frames it has been held
+func (b *buttonState) setDown(id int, player int, down bool) {
+	b.down[player][id] = down
+	if down {
+		b.held[player][id]++
+	} else {
+		b.held[player][id] = 0
+	}
+}
+
+// btn - is button currently pressed
+func (b *buttonState) btn(id int, player int) bool {
+	if !validButton(id, player) {
+		return false
+	}
+	return b.down[player][id]
+}
+
+// btnp - was button pressed this frame, repeats while held
+func (b *buttonState) btnp(id int, player int) bool {
+	if !validButton(id, player) {
+		return false
+	}
+	held := b.held[player][id]
+	if held == 1 {
+		return true
+	}
+	if held > btnpDelay && (held-btnpDelay-1)%btnpRepeat == 0 {
+		return true
+	}
+	return false
+}
+
+func validButton(id int, player int) bool {
+	return id >= 0 && id < totalButtons && player >= 0 && player < totalPlayers
+}
+
+// InputState - button & mouse state for a single frame
+type InputState struct {
+	Buttons      [totalPlayers]uint8 // bit per button id for each player
+	MouseX       int
+	MouseY       int
+	MouseButtons int
+	MouseWheel   int
+}
+
+// InputFunc - returns the input state for a frame number, starting at 0
+type InputFunc func(frame int) InputState
+
+// SetInput - feed input from f instead of the keyboard & mouse, nil restores live input
+func SetInput(f InputFunc) {
+	_console.Lock()
+	defer _console.Unlock()
+	_console.input = f
+	_console.replay = nil
+}
+
+// setInput - applies input state in place of live input
+func (c *console) setInput(in InputState) {
+	c.Lock()
+	defer c.Unlock()
+	for player := range in.Buttons {
+		for id := 0; id < totalButtons; id++ {
+			c.buttons.setDown(id, player, in.Buttons[player]&(1<<uint(id)) != 0)
+		}
+	}
+	c.mouse = mouseState{
+		x:       in.MouseX,
+		y:       in.MouseY,
+		buttons: in.MouseButtons,
+		wheel:   in.MouseWheel,
+	}
+}
+
+// Btn - is button pressed for player (default player 0)
+func (c *console) Btn(id int, player ...int) bool {
+	c.Lock()
+	defer c.Unlock()
+	if c.buttons == nil {
+		return false
+	}
+	return c.buttons.btn(id, playerID(player))
+}
+
+// Btnp - was button just pressed for player (default player 0)
+func (c *console) Btnp(id int, player ...int) bool {
+	c.Lock()
+	defer c.Unlock()
+	if c.buttons == nil {
+		return false
+	}
+	return c.buttons.btnp(id, playerID(player))
+}
+
+func playerID(player []int) int {
+	if len(player) == 0 {
+		return 0
+	}
+	return player[0]
+}
@@ -0,0 +1,89 @@
+package console
+
+import (
+	"testing"
+)
+
+func TestBtn(t *testing.T) {
+	b := newButtonState(newKeyBindings(PICO8))
+
+	pressed := map[Key]bool{KEY_LEFT: true, KEY_A: true}
+	b.sample(func(key Key) bool { return pressed[key] })
+
+	if !b.btn(BTN_LEFT, 0) {
+		t.Errorf("Expected player 0 left to be pressed")
+	}
+	if b.btn(BTN_RIGHT, 0) {
+		t.Errorf("Expected player 0 right not to be pressed")
+	}
+	if !b.btn(BTN_X, 1) {
+		t.Errorf("Expected player 1 X to be pressed")
+	}
+	if b.btn(BTN_LEFT, 2) || b.btn(totalButtons, 0) {
+		t.Errorf("Expected invalid buttons not to be pressed")
+	}
+}
+
+func TestBtnpRepeat(t *testing.T) {
+	b := newButtonState(newKeyBindings(PICO8))
+	down := func(key Key) bool { return key == KEY_Z }
+
+	// record which frames btnp fires on while button held
+	fired := make([]int, 0)
+	for frame := 1; frame <= btnpDelay+btnpRepeat*2+1; frame++ {
+		b.sample(down)
+		if b.btnp(BTN_O, 0) {
+			fired = append(fired, frame)
+		}
+	}
+
+	expected := []int{1, btnpDelay + 1, btnpDelay + btnpRepeat + 1, btnpDelay + btnpRepeat*2 + 1}
+	if len(fired) != len(expected) {
+		t.Fatalf("Expected btnp on frames: %v got: %v", expected, fired)
+	}
+	for i := range expected {
+		if fired[i] != expected[i] {
+			t.Fatalf("Expected btnp on frames: %v got: %v", expected, fired)
+		}
+	}
+
+	// releasing resets the repeat
+	b.sample(func(key Key) bool { return false })
+	if b.btnp(BTN_O, 0) {
+		t.Errorf("Expected btnp to be false once released")
+	}
+	b.sample(down)
+	if !b.btnp(BTN_O, 0) {
+		t.Errorf("Expected btnp to be true when pressed again")
+	}
+}
+
+func TestSetInput(t *testing.T) {
+	if err := InitHeadless(PICO8); err != nil {
+		t.Fatalf("Failed to init headless console: %s", err)
+	}
+	defer SetInput(nil)
+
+	// hold X for player 1 from frame 2 & move the mouse each frame
+	SetInput(func(frame int) InputState {
+		in := InputState{MouseX: frame, MouseY: frame * 2}
+		if frame >= 2 {
+			in.Buttons[1] = 1 << BTN_X
+		}
+		return in
+	})
+
+	cart := &countingCart{BaseCartridge: NewBaseCart()}
+	if err := RunHeadless(cart, 4); err != nil {
+		t.Fatalf("Failed to run headless: %s", err)
+	}
+	if !cart.Btn(BTN_X, 1) || cart.Btn(BTN_X, 0) {
+		t.Errorf("Expected only player 1 X to be pressed")
+	}
+	if cart.Btnp(BTN_X, 1) {
+		t.Errorf("Expected btnp to be false after X held for 2 frames")
+	}
+	if cart.Stat(STAT_MOUSE_X) != 3 || cart.Stat(STAT_MOUSE_Y) != 6 {
+		t.Errorf("Expected mouse at 3,6 but got %d,%d", cart.Stat(STAT_MOUSE_X), cart.Stat(STAT_MOUSE_Y))
+	}
+}
@@ -0,0 +1,302 @@
+package console
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+)
+
+// KeyBindings - keyboard keys for each button of each player
+type KeyBindings [totalPlayers][totalButtons][]Key
+
+// button names used in key binding files
+var buttonNames = [totalButtons]string{
+	BTN_LEFT:  "left",
+	BTN_RIGHT: "right",
+	BTN_UP:    "up",
+	BTN_DOWN:  "down",
+	BTN_O:     "o",
+	BTN_X:     "x",
+}
+
+// default key bindings for different console types
+var defaultKeyBindings = map[ConsoleType]KeyBindings{
+	PICO8: {
+		{
+			BTN_LEFT:  {KEY_LEFT},
+			BTN_RIGHT: {KEY_RIGHT},
+			BTN_UP:    {KEY_UP},
+			BTN_DOWN:  {KEY_DOWN},
+			BTN_O:     {KEY_Z, KEY_C, KEY_N},
+			BTN_X:     {KEY_X, KEY_V, KEY_M},
+		},
+		{
+			BTN_LEFT:  {KEY_S},
+			BTN_RIGHT: {KEY_F},
+			BTN_UP:    {KEY_E},
+			BTN_DOWN:  {KEY_D},
+			BTN_O:     {KEY_SHIFT, KEY_TAB},
+			BTN_X:     {KEY_A, KEY_Q},
+		},
+	},
+	TIC80: {
+		{
+			BTN_LEFT:  {KEY_LEFT},
+			BTN_RIGHT: {KEY_RIGHT},
+			BTN_UP:    {KEY_UP},
+			BTN_DOWN:  {KEY_DOWN},
+			BTN_O:     {KEY_Z},
+			BTN_X:     {KEY_X},
+		},
+		{
+			BTN_LEFT:  {KEY_S},
+			BTN_RIGHT: {KEY_F},
+			BTN_UP:    {KEY_E},
+			BTN_DOWN:  {KEY_D},
+			BTN_O:     {KEY_SHIFT, KEY_TAB},
+			BTN_X:     {KEY_A, KEY_Q},
+		},
+	},
+	ZXSPECTRUM: {
+		// classic QAOP + space
+		{
+			BTN_LEFT:  {KEY_O, KEY_LEFT},
+			BTN_RIGHT: {KEY_P, KEY_RIGHT},
+			BTN_UP:    {KEY_Q, KEY_UP},
+			BTN_DOWN:  {KEY_A, KEY_DOWN},
+			BTN_O:     {KEY_SPACE},
+			BTN_X:     {KEY_M},
+		},
+		// sinclair interface 2 joystick keys
+		{
+			BTN_LEFT:  {KEY_6},
+			BTN_RIGHT: {KEY_7},
+			BTN_UP:    {KEY_9},
+			BTN_DOWN:  {KEY_8},
+			BTN_O:     {KEY_0},
+			BTN_X:     {KEY_5},
+		},
+	},
+	CBM64: {
+		{
+			BTN_LEFT:  {KEY_LEFT},
+			BTN_RIGHT: {KEY_RIGHT},
+			BTN_UP:    {KEY_UP},
+			BTN_DOWN:  {KEY_DOWN},
+			BTN_O:     {KEY_Z},
+			BTN_X:     {KEY_X},
+		},
+		{
+			BTN_LEFT:  {KEY_S},
+			BTN_RIGHT: {KEY_F},
+			BTN_UP:    {KEY_E},
+			BTN_DOWN:  {KEY_D},
+			BTN_O:     {KEY_SHIFT, KEY_TAB},
+			BTN_X:     {KEY_A, KEY_Q},
+		},
+	},
+}
+
+// newKeyBindings - returns a copy of the default key bindings for console type
+func newKeyBindings(consoleType ConsoleType) KeyBindings {
+	defaults, ok := defaultKeyBindings[consoleType]
+	if !ok {
+		defaults = defaultKeyBindings[PICO8]
+	}
+	return defaults.copy()
+}
+
+// copy - deep copy of bindings so key slices are not shared
+func (k KeyBindings) copy() KeyBindings {
+	var c KeyBindings
+	for player := range k {
+		for id, keys := range k[player] {
+			c[player][id] = append([]Key(nil), keys...)
+		}
+	}
+	return c
+}
+
+// Bind - replaces the keys for a player's button
+func (k *KeyBindings) Bind(id, player int, keys ...Key) error {
+	if !validButton(id, player) {
+		return fmt.Errorf("Error binding keys - invalid button: %d for player: %d", id, player)
+	}
+	k[player][id] = append([]Key(nil), keys...)
+	return nil
+}
+
+// MarshalJSON - encodes bindings as a list of players mapping button names to key names
+func (k KeyBindings) MarshalJSON() ([]byte, error) {
+	players := make([]map[string][]string, totalPlayers)
+	for player := range k {
+		players[player] = make(map[string][]string, totalButtons)
+		for id, keys := range k[player] {
+			names := make([]string, len(keys))
+			for i, key := range keys {
+				names[i] = key.String()
+			}
+			players[player][buttonNames[id]] = names
+		}
+	}
+	return json.Marshal(players)
+}
+
+// UnmarshalJSON - decodes bindings, buttons not in the JSON are left unchanged
+func (k *KeyBindings) UnmarshalJSON(data []byte) error {
+	players := make([]map[string][]string, 0, totalPlayers)
+	if err := json.Unmarshal(data, &players); err != nil {
+		return err
+	}
+	if len(players) > totalPlayers {
+		return fmt.Errorf("Error decoding key bindings - too many players: %d", len(players))
+	}
+	for player, buttons := range players {
+		for name, keyNames := range buttons {
+			id := buttonID(name)
+			if id < 0 {
+				return fmt.Errorf("Error decoding key bindings - unknown button: %s", name)
+			}
+			keys := make([]Key, len(keyNames))
+			for i, keyName := range keyNames {
+				key, ok := keyByName(keyName)
+				if !ok {
+					return fmt.Errorf("Error decoding key bindings - unknown key: %s", keyName)
+				}
+				keys[i] = key
+			}
+			k[player][id] = keys
+		}
+	}
+	return nil
+}
+
+func buttonID(name string) int {
+	for id, buttonName := range buttonNames {
+		if strings.EqualFold(name, buttonName) {
+			return id
+		}
+	}
+	return -1
+}
+
+func keyByName(name string) (Key, bool) {
+	for key, keyName := range keyNames {
+		if strings.EqualFold(name, keyName) {
+			return Key(key), true
+		}
+	}
+	return 0, false
+}
+
+// LoadKeyBindings - loads bindings for this console type from a JSON file
+// the file holds bindings for each console type, eg. {"pico8": [...], "tic80": [...]}
+// if the file has no entry for this console type the bindings are unchanged
+func (c *Config) LoadKeyBindings(filename string) error {
+	all, err := readKeyBindingsFile(filename)
+	if err != nil {
+		return err
+	}
+	if bindings, ok := all[c.consoleType]; ok {
+		c.KeyBindings = *bindings
+	}
+	return nil
+}
+
+// SaveKeyBindings - saves bindings for this console type to a JSON file
+// bindings for other console types already in the file are kept
+func (c *Config) SaveKeyBindings(filename string) error {
+	all, err := readKeyBindingsFile(filename)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if all == nil {
+		all = make(map[ConsoleType]*KeyBindings)
+	}
+	bindings := c.KeyBindings.copy()
+	all[c.consoleType] = &bindings
+
+	data, err := json.MarshalIndent(all, "", "  ")
+	if err != nil {
+		return fmt.Errorf("Error encoding key bindings: %s", err)
+	}
+	return ioutil.WriteFile(filename, data, 0644)
+}
+
+func readKeyBindingsFile(filename string) (map[ConsoleType]*KeyBindings, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	raw := make(map[ConsoleType]json.RawMessage)
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("Error decoding key bindings file: %s - %s", filename, err)
+	}
+	all := make(map[ConsoleType]*KeyBindings, len(raw))
+	for consoleType, rawBindings := range raw {
+		// start from defaults so partial files only override some buttons
+		bindings := newKeyBindings(consoleType)
+		if err := json.Unmarshal(rawBindings, &bindings); err != nil {
+			return nil, fmt.Errorf("Error decoding key bindings file: %s - %s", filename, err)
+		}
+		all[consoleType] = &bindings
+	}
+	return all, nil
+}
+
+// Runtime key binding API
+
+// GetKeyBindings - returns a copy of the running console's key bindings
+func GetKeyBindings() KeyBindings {
+	_console.Lock()
+	defer _console.Unlock()
+	return _console.Config.KeyBindings.copy()
+}
+
+// SetKeyBindings - replaces the running console's key bindings
+func SetKeyBindings(bindings KeyBindings) {
+	_console.Lock()
+	defer _console.Unlock()
+	_console.Config.KeyBindings = bindings.copy()
+	_console.updateButtonKeys()
+}
+
+// BindButton - rebinds a single button of the running console, eg. from a settings screen
+func BindButton(id, player int, keys ...Key) error {
+	_console.Lock()
+	defer _console.Unlock()
+	if err := _console.Config.KeyBindings.Bind(id, player, keys...); err != nil {
+		return err
+	}
+	_console.updateButtonKeys()
+	return nil
+}
+
+// LoadKeyBindings - loads key bindings for the running console from a JSON file
+func LoadKeyBindings(filename string) error {
+	_console.Lock()
+	defer _console.Unlock()
+	if err := _console.Config.LoadKeyBindings(filename); err != nil {
+		return err
+	}
+	_console.updateButtonKeys()
+	return nil
+}
+
+// updateButtonKeys - buttons are sampled with the configured key bindings,
+// before Init there are no buttons and only the config is changed
+func (c *console) updateButtonKeys() {
+	if c.buttons == nil {
+		return
+	}
+	c.buttons.keys = c.Config.KeyBindings
+}
+
+// SaveKeyBindings - saves key bindings of the running console to a JSON file
+func SaveKeyBindings(filename string) error {
+	_console.Lock()
+	defer _console.Unlock()
+	return _console.Config.SaveKeyBindings(filename)
+}
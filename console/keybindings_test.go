@@ -0,0 +1,122 @@
+package console
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestKeyBindingsSaveLoad(t *testing.T) {
+	dir, err := ioutil.TempDir("", "keybindings")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "keys.json")
+
+	cfg := NewConfig(PICO8)
+	if err := cfg.KeyBindings.Bind(BTN_O, 0, KEY_J, KEY_K); err != nil {
+		t.Fatalf("Failed to bind keys: %s", err)
+	}
+	if err := cfg.SaveKeyBindings(filename); err != nil {
+		t.Fatalf("Failed to save key bindings: %s", err)
+	}
+
+	// other console types are unaffected
+	tic80 := NewConfig(TIC80)
+	if err := tic80.LoadKeyBindings(filename); err != nil {
+		t.Fatalf("Failed to load key bindings: %s", err)
+	}
+	if tic80.KeyBindings[0][BTN_O][0] != KEY_Z {
+		t.Errorf("Expected TIC80 bindings to be unchanged got: %v", tic80.KeyBindings[0][BTN_O])
+	}
+
+	loaded := NewConfig(PICO8)
+	if err := loaded.LoadKeyBindings(filename); err != nil {
+		t.Fatalf("Failed to load key bindings: %s", err)
+	}
+	keys := loaded.KeyBindings[0][BTN_O]
+	if len(keys) != 2 || keys[0] != KEY_J || keys[1] != KEY_K {
+		t.Errorf("Expected keys: %v got: %v", []Key{KEY_J, KEY_K}, keys)
+	}
+	if loaded.KeyBindings[1][BTN_LEFT][0] != KEY_S {
+		t.Errorf("Expected player 1 left to be unchanged got: %v", loaded.KeyBindings[1][BTN_LEFT])
+	}
+}
+
+func TestKeyBindingsPartialFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "keybindings")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "keys.json")
+
+	data := []byte(`{"pico8": [{"x": ["Space"]}]}`)
+	if err := ioutil.WriteFile(filename, data, 0644); err != nil {
+		t.Fatalf("Failed to write key bindings: %s", err)
+	}
+
+	cfg := NewConfig(PICO8)
+	if err := cfg.LoadKeyBindings(filename); err != nil {
+		t.Fatalf("Failed to load key bindings: %s", err)
+	}
+	if keys := cfg.KeyBindings[0][BTN_X]; len(keys) != 1 || keys[0] != KEY_SPACE {
+		t.Errorf("Expected X to be bound to space got: %v", keys)
+	}
+	if keys := cfg.KeyBindings[0][BTN_LEFT]; len(keys) != 1 || keys[0] != KEY_LEFT {
+		t.Errorf("Expected left to keep default binding got: %v", keys)
+	}
+
+	bad := []byte(`{"pico8": [{"jump": ["Space"]}]}`)
+	if err := ioutil.WriteFile(filename, bad, 0644); err != nil {
+		t.Fatalf("Failed to write key bindings: %s", err)
+	}
+	if err := cfg.LoadKeyBindings(filename); err == nil {
+		t.Errorf("Expected error for unknown button")
+	}
+}
+
+func TestBindButton(t *testing.T) {
+	Init(PICO8)
+
+	if err := BindButton(BTN_LEFT, 0, KEY_H); err != nil {
+		t.Fatalf("Failed to bind button: %s", err)
+	}
+	_console.buttons.sample(func(key Key) bool { return key == KEY_H })
+	if !_console.Btn(BTN_LEFT) {
+		t.Errorf("Expected rebound key to press left")
+	}
+
+	if err := BindButton(totalButtons, 0, KEY_H); err == nil {
+		t.Errorf("Expected error binding invalid button")
+	}
+}
+
+func TestKeyBindingsBeforeInit(t *testing.T) {
+	defer Init(PICO8)
+
+	// without buttons only the config is changed
+	_console.buttons = nil
+	SetKeyBindings(newKeyBindings(PICO8))
+	if err := BindButton(BTN_LEFT, 0, KEY_H); err != nil {
+		t.Fatalf("Failed to bind button: %s", err)
+	}
+	if keys := GetKeyBindings()[0][BTN_LEFT]; len(keys) != 1 || keys[0] != KEY_H {
+		t.Errorf("Expected left bound to H got: %v", keys)
+	}
+
+	dir, err := ioutil.TempDir("", "keys")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+	filename := filepath.Join(dir, "keys.json")
+	if err := SaveKeyBindings(filename); err != nil {
+		t.Fatalf("Failed to save key bindings: %s", err)
+	}
+	if err := LoadKeyBindings(filename); err != nil {
+		t.Fatalf("Failed to load key bindings: %s", err)
+	}
+}
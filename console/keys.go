@@ -0,0 +1,220 @@
+package console
+
+// Key - keyboard key, keys are the physical keys of a US keyboard
+type Key int
+
+// Keys
+const (
+	KEY_0 Key = iota
+	KEY_1
+	KEY_2
+	KEY_3
+	KEY_4
+	KEY_5
+	KEY_6
+	KEY_7
+	KEY_8
+	KEY_9
+	KEY_A
+	KEY_B
+	KEY_C
+	KEY_D
+	KEY_E
+	KEY_F
+	KEY_G
+	KEY_H
+	KEY_I
+	KEY_J
+	KEY_K
+	KEY_L
+	KEY_M
+	KEY_N
+	KEY_O
+	KEY_P
+	KEY_Q
+	KEY_R
+	KEY_S
+	KEY_T
+	KEY_U
+	KEY_V
+	KEY_W
+	KEY_X
+	KEY_Y
+	KEY_Z
+	KEY_ALT
+	KEY_APOSTROPHE
+	KEY_BACKSLASH
+	KEY_BACKSPACE
+	KEY_CAPS_LOCK
+	KEY_COMMA
+	KEY_CONTROL
+	KEY_DELETE
+	KEY_DOWN
+	KEY_END
+	KEY_ENTER
+	KEY_EQUAL
+	KEY_ESCAPE
+	KEY_F1
+	KEY_F2
+	KEY_F3
+	KEY_F4
+	KEY_F5
+	KEY_F6
+	KEY_F7
+	KEY_F8
+	KEY_F9
+	KEY_F10
+	KEY_F11
+	KEY_F12
+	KEY_GRAVE_ACCENT
+	KEY_HOME
+	KEY_INSERT
+	KEY_KP_0
+	KEY_KP_1
+	KEY_KP_2
+	KEY_KP_3
+	KEY_KP_4
+	KEY_KP_5
+	KEY_KP_6
+	KEY_KP_7
+	KEY_KP_8
+	KEY_KP_9
+	KEY_KP_ADD
+	KEY_KP_DECIMAL
+	KEY_KP_DIVIDE
+	KEY_KP_ENTER
+	KEY_KP_EQUAL
+	KEY_KP_MULTIPLY
+	KEY_KP_SUBTRACT
+	KEY_LEFT
+	KEY_LEFT_BRACKET
+	KEY_MENU
+	KEY_MINUS
+	KEY_NUM_LOCK
+	KEY_PAGE_DOWN
+	KEY_PAGE_UP
+	KEY_PAUSE
+	KEY_PERIOD
+	KEY_PRINT_SCREEN
+	KEY_RIGHT
+	KEY_RIGHT_BRACKET
+	KEY_SCROLL_LOCK
+	KEY_SEMICOLON
+	KEY_SHIFT
+	KEY_SLASH
+	KEY_SPACE
+	KEY_TAB
+	KEY_UP
+)
+
+// keyNames - key names used in key binding files
+var keyNames = [...]string{
+	KEY_0:             "0",
+	KEY_1:             "1",
+	KEY_2:             "2",
+	KEY_3:             "3",
+	KEY_4:             "4",
+	KEY_5:             "5",
+	KEY_6:             "6",
+	KEY_7:             "7",
+	KEY_8:             "8",
+	KEY_9:             "9",
+	KEY_A:             "A",
+	KEY_B:             "B",
+	KEY_C:             "C",
+	KEY_D:             "D",
+	KEY_E:             "E",
+	KEY_F:             "F",
+	KEY_G:             "G",
+	KEY_H:             "H",
+	KEY_I:             "I",
+	KEY_J:             "J",
+	KEY_K:             "K",
+	KEY_L:             "L",
+	KEY_M:             "M",
+	KEY_N:             "N",
+	KEY_O:             "O",
+	KEY_P:             "P",
+	KEY_Q:             "Q",
+	KEY_R:             "R",
+	KEY_S:             "S",
+	KEY_T:             "T",
+	KEY_U:             "U",
+	KEY_V:             "V",
+	KEY_W:             "W",
+	KEY_X:             "X",
+	KEY_Y:             "Y",
+	KEY_Z:             "Z",
+	KEY_ALT:           "Alt",
+	KEY_APOSTROPHE:    "Apostrophe",
+	KEY_BACKSLASH:     "Backslash",
+	KEY_BACKSPACE:     "Backspace",
+	KEY_CAPS_LOCK:     "CapsLock",
+	KEY_COMMA:         "Comma",
+	KEY_CONTROL:       "Control",
+	KEY_DELETE:        "Delete",
+	KEY_DOWN:          "Down",
+	KEY_END:           "End",
+	KEY_ENTER:         "Enter",
+	KEY_EQUAL:         "Equal",
+	KEY_ESCAPE:        "Escape",
+	KEY_F1:            "F1",
+	KEY_F2:            "F2",
+	KEY_F3:            "F3",
+	KEY_F4:            "F4",
+	KEY_F5:            "F5",
+	KEY_F6:            "F6",
+	KEY_F7:            "F7",
+	KEY_F8:            "F8",
+	KEY_F9:            "F9",
+	KEY_F10:           "F10",
+	KEY_F11:           "F11",
+	KEY_F12:           "F12",
+	KEY_GRAVE_ACCENT:  "GraveAccent",
+	KEY_HOME:          "Home",
+	KEY_INSERT:        "Insert",
+	KEY_KP_0:          "KP0",
+	KEY_KP_1:          "KP1",
+	KEY_KP_2:          "KP2",
+	KEY_KP_3:          "KP3",
+	KEY_KP_4:          "KP4",
+	KEY_KP_5:          "KP5",
+	KEY_KP_6:          "KP6",
+	KEY_KP_7:          "KP7",
+	KEY_KP_8:          "KP8",
+	KEY_KP_9:          "KP9",
+	KEY_KP_ADD:        "KPAdd",
+	KEY_KP_DECIMAL:    "KPDecimal",
+	KEY_KP_DIVIDE:     "KPDivide",
+	KEY_KP_ENTER:      "KPEnter",
+	KEY_KP_EQUAL:      "KPEqual",
+	KEY_KP_MULTIPLY:   "KPMultiply",
+	KEY_KP_SUBTRACT:   "KPSubtract",
+	KEY_LEFT:          "Left",
+	KEY_LEFT_BRACKET:  "LeftBracket",
+	KEY_MENU:          "Menu",
+	KEY_MINUS:         "Minus",
+	KEY_NUM_LOCK:      "NumLock",
+	KEY_PAGE_DOWN:     "PageDown",
+	KEY_PAGE_UP:       "PageUp",
+	KEY_PAUSE:         "Pause",
+	KEY_PERIOD:        "Period",
+	KEY_PRINT_SCREEN:  "PrintScreen",
+	KEY_RIGHT:         "Right",
+	KEY_RIGHT_BRACKET: "RightBracket",
+	KEY_SCROLL_LOCK:   "ScrollLock",
+	KEY_SEMICOLON:     "Semicolon",
+	KEY_SHIFT:         "Shift",
+	KEY_SLASH:         "Slash",
+	KEY_SPACE:         "Space",
+	KEY_TAB:           "Tab",
+	KEY_UP:            "Up",
+}
+
+// String - returns name of the key, empty for unknown keys
+func (k Key) String() string {
+	if k < 0 || int(k) >= len(keyNames) {
+		return ""
+	}
+	return keyNames[k]
+}
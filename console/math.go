@@ -0,0 +1,75 @@
+package console
+
+import (
+	"math"
+	"math/rand"
+)
+
+/*
+	Math functions follow pico8 semantics
+
+	Angles are measured in turns (0..1) rather than radians and
+	Sin is inverted so angles go anti-clockwise on screen, where
+	the y axis points down.
+*/
+
+// Sin - sine of x turns, inverted for screen coords
+func (c *console) Sin(x float64) float64 {
+	return -math.Sin(x * 2 * math.Pi)
+}
+
+// Cos - cosine of x turns
+func (c *console) Cos(x float64) float64 {
+	return math.Cos(x * 2 * math.Pi)
+}
+
+// Atan2 - angle in turns (0..1) of vector dx,dy, inverted for screen coords
+func (c *console) Atan2(dx, dy float64) float64 {
+	// pico8 treats a zero vector as pointing up
+	if dx == 0 && dy == 0 {
+		return 0.25
+	}
+	a := math.Atan2(-dy, dx) / (2 * math.Pi)
+	if a < 0 {
+		a++
+	}
+	return a
+}
+
+// Rnd - random number from 0 up to but not including max
+func (c *console) Rnd(max float64) float64 {
+	c.Lock()
+	defer c.Unlock()
+	if c.rng == nil {
+		c.rng = rand.New(rand.NewSource(0))
+	}
+	return c.rng.Float64() * max
+}
+
+// Srand - seed the console random number generator
+func (c *console) Srand(seed int64) {
+	c.Lock()
+	defer c.Unlock()
+	c.rng = rand.New(rand.NewSource(seed))
+}
+
+// Mid - middle value of x, y & z
+func (c *console) Mid(x, y, z float64) float64 {
+	if x > y {
+		x, y = y, x
+	}
+	return math.Max(x, math.Min(y, z))
+}
+
+// Flr - largest integer less than or equal to x
+func (c *console) Flr(x float64) float64 {
+	return math.Floor(x)
+}
+
+// Sgn - sign of x, 1 for zero or positive and -1 for negative
+func (c *console) Sgn(x float64) float64 {
+	if x < 0 {
+		return -1
+	}
+	return 1
+}
@@ -0,0 +1,101 @@
+package console
+
+import (
+	"math"
+	"testing"
+)
+
+const epsilon = 1e-9
+
+func TestTurns(t *testing.T) {
+	c := &console{}
+
+	type turnTest struct {
+		x   float64
+		sin float64
+		cos float64
+	}
+	tests := []turnTest{
+		{x: 0, sin: 0, cos: 1},
+		{x: 0.25, sin: -1, cos: 0},
+		{x: 0.5, sin: 0, cos: -1},
+		{x: 0.75, sin: 1, cos: 0},
+		{x: 1, sin: 0, cos: 1},
+	}
+	for _, test := range tests {
+		if math.Abs(c.Sin(test.x)-test.sin) > epsilon {
+			t.Errorf("Sin(%v): expected %v got %v", test.x, test.sin, c.Sin(test.x))
+		}
+		if math.Abs(c.Cos(test.x)-test.cos) > epsilon {
+			t.Errorf("Cos(%v): expected %v got %v", test.x, test.cos, c.Cos(test.x))
+		}
+	}
+}
+
+func TestAtan2(t *testing.T) {
+	c := &console{}
+
+	type atan2Test struct {
+		dx, dy   float64
+		expected float64
+	}
+	tests := []atan2Test{
+		{dx: 1, dy: 0, expected: 0},
+		{dx: 0, dy: -1, expected: 0.25},
+		{dx: -1, dy: 0, expected: 0.5},
+		{dx: 0, dy: 1, expected: 0.75},
+		{dx: 1, dy: 1, expected: 0.875},
+		{dx: 0, dy: 0, expected: 0.25},
+	}
+	for _, test := range tests {
+		if a := c.Atan2(test.dx, test.dy); math.Abs(a-test.expected) > epsilon {
+			t.Errorf("Atan2(%v, %v): expected %v got %v", test.dx, test.dy, test.expected, a)
+		}
+	}
+}
+
+func TestRndSrand(t *testing.T) {
+	c := &console{}
+
+	c.Srand(42)
+	first := make([]float64, 10)
+	for i := range first {
+		first[i] = c.Rnd(10)
+		if first[i] < 0 || first[i] >= 10 {
+			t.Errorf("Rnd(10): expected 0 <= x < 10 got %v", first[i])
+		}
+	}
+
+	// same seed repeats the same numbers
+	c.Srand(42)
+	for i := range first {
+		if r := c.Rnd(10); r != first[i] {
+			t.Fatalf("Rnd after Srand: expected %v got %v", first[i], r)
+		}
+	}
+}
+
+func TestMidFlrSgn(t *testing.T) {
+	c := &console{}
+
+	mids := [][4]float64{
+		{1, 2, 3, 2},
+		{3, 2, 1, 2},
+		{2, 3, 1, 2},
+		{1, 1, 5, 1},
+		{-1, 5, 10, 5},
+	}
+	for _, m := range mids {
+		if mid := c.Mid(m[0], m[1], m[2]); mid != m[3] {
+			t.Errorf("Mid(%v, %v, %v): expected %v got %v", m[0], m[1], m[2], m[3], mid)
+		}
+	}
+
+	if c.Flr(1.5) != 1 || c.Flr(-1.5) != -2 || c.Flr(2) != 2 {
+		t.Errorf("Flr: expected 1, -2 & 2 got %v, %v & %v", c.Flr(1.5), c.Flr(-1.5), c.Flr(2))
+	}
+
+	if c.Sgn(5) != 1 || c.Sgn(0) != 1 || c.Sgn(-0.5) != -1 {
+		t.Errorf("Sgn: expected 1, 1 & -1 got %v, %v & %v", c.Sgn(5), c.Sgn(0), c.Sgn(-0.5))
+	}
+}
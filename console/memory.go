@@ -0,0 +1,429 @@
+package console
+
+/*
+	Memory - a virtual 64KB of RAM laid out like pico8
+
+	0x0000 sprite sheet of current bank, 2 pixels per byte, low nibble is the left pixel
+	0x1000 lower half of sprite sheet
+	0x2000 map, 1 sprite number per cell
+	0x3000 sprite flags
+	0x3100 music patterns, 4 bytes each
+	0x3200 sfx, 68 bytes each
+	0x4300 general use
+	0x5e00 persistent cart data
+	0x5f00 draw state
+	0x5f40 hardware state
+	0x6000 screen, 2 pixels per byte, low nibble is the left pixel
+	0x8000 general use
+
+	Regions backed by console state such as the sprite sheet, map and screen
+	are read and written directly, so memory and drawing are always in sync.
+	Sprite sheet, map and screen regions map onto the first 16384 pixels or
+	8192 cells in row order, for pico8 this is the whole sprite sheet & screen
+	and the upper half of the map.
+*/
+
+import "image"
+
+// Memory map addresses
+const (
+	MEM_SPRITES      = 0x0000
+	MEM_MAP          = 0x2000
+	MEM_SPRITE_FLAGS = 0x3000
+	MEM_MUSIC        = 0x3100
+	MEM_SFX          = 0x3200
+	MEM_USER_DATA    = 0x4300
+	MEM_CART_DATA    = 0x5e00
+	MEM_DRAW_STATE   = 0x5f00
+	MEM_HARDWARE     = 0x5f40
+	MEM_SCREEN       = 0x6000
+	MEM_UPPER        = 0x8000
+	MEM_SIZE         = 0x10000
+)
+
+// Draw state addresses
+const (
+	_memDrawPal   = 0x5f00 // draw palette, bit 4 set when color is transparent
+	_memScreenPal = 0x5f10 // screen palette
+	_memClip      = 0x5f20 // clip rect x0, y0, x1, y1
+	_memColor     = 0x5f25 // drawing color
+	_memCursor    = 0x5f26 // print cursor x, y in pixels
+	_memCamera    = 0x5f28 // camera x, y as 16 bit values
+	_memFillp     = 0x5f31 // fill pattern as 16 bit value, then transparency bit
+)
+
+const (
+	_sfxBytes   = 68
+	_musicBytes = 4
+)
+
+// Peek - read byte from memory address
+func (p *pixelBuffer) Peek(addr int) uint8 {
+	switch {
+	case addr < 0 || addr >= MEM_SIZE:
+		return 0
+	case addr < MEM_MAP:
+		return peekPixels(spriteSheet().Pix, addr-MEM_SPRITES)
+	case addr < MEM_SPRITE_FLAGS:
+		return peekBytes(_console.tilemap, addr-MEM_MAP)
+	case addr < MEM_MUSIC:
+		return peekBytes(bankFlags(), addr-MEM_SPRITE_FLAGS)
+	case addr < MEM_SFX:
+		offset := addr - MEM_MUSIC
+		return _console.synth.musicByte(offset/_musicBytes, offset%_musicBytes)
+	case addr < MEM_USER_DATA:
+		offset := addr - MEM_SFX
+		return _console.synth.sfxByte(offset/_sfxBytes, offset%_sfxBytes)
+	case addr >= MEM_DRAW_STATE && addr < MEM_HARDWARE:
+		return p.peekDrawState(addr)
+	case addr >= MEM_SCREEN && addr < MEM_UPPER:
+		return peekPixels(p.pixelSurface.Pix, addr-MEM_SCREEN)
+	}
+	return _console.ram[addr]
+}
+
+// Poke - write byte to memory address
+func (p *pixelBuffer) Poke(addr int, value uint8) {
+	switch {
+	case addr < 0 || addr >= MEM_SIZE:
+		return
+	case addr < MEM_MAP:
+		offset := addr - MEM_SPRITES
+		pokePixels(spriteSheet().Pix, offset, value)
+		// transformed sprites are stale
+		if len(p.spriteCache) > 0 {
+			p.spriteCache = make(map[spriteTx]spriteCached)
+		}
+	case addr < MEM_SPRITE_FLAGS:
+		pokeBytes(_console.tilemap, addr-MEM_MAP, value)
+	case addr < MEM_MUSIC:
+		pokeBytes(bankFlags(), addr-MEM_SPRITE_FLAGS, value)
+	case addr < MEM_SFX:
+		offset := addr - MEM_MUSIC
+		_console.synth.setMusicByte(offset/_musicBytes, offset%_musicBytes, value)
+	case addr < MEM_USER_DATA:
+		offset := addr - MEM_SFX
+		_console.synth.setSfxByte(offset/_sfxBytes, offset%_sfxBytes, value)
+	case addr >= MEM_DRAW_STATE && addr < MEM_HARDWARE:
+		p.pokeDrawState(addr, value)
+		_console.ram[addr] = value
+	case addr >= MEM_SCREEN && addr < MEM_UPPER:
+		pokePixels(p.pixelSurface.Pix, addr-MEM_SCREEN, value)
+	default:
+		_console.ram[addr] = value
+	}
+}
+
+// Peek2 - read 16 bit signed value, low byte first
+func (p *pixelBuffer) Peek2(addr int) int {
+	return int(int16(uint16(p.Peek(addr)) | uint16(p.Peek(addr+1))<<8))
+}
+
+// Poke2 - write 16 bit value, low byte first
+func (p *pixelBuffer) Poke2(addr int, value int) {
+	p.Poke(addr, uint8(value))
+	p.Poke(addr+1, uint8(value>>8))
+}
+
+// Peek4 - read 32 bit fixed point value, low byte first
+func (p *pixelBuffer) Peek4(addr int) Fix {
+	var v uint32
+	for i := uint(0); i < 4; i++ {
+		v |= uint32(p.Peek(addr+int(i))) << (8 * i)
+	}
+	return Fix(v)
+}
+
+// Poke4 - write 32 bit fixed point value, low byte first
+func (p *pixelBuffer) Poke4(addr int, value Fix) {
+	for i := uint(0); i < 4; i++ {
+		p.Poke(addr+int(i), uint8(uint32(value)>>(8*i)))
+	}
+}
+
+// Memcpy - copy length bytes from src to dest, the regions may overlap
+func (p *pixelBuffer) Memcpy(dest, src, length int) {
+	if length <= 0 {
+		return
+	}
+	buf := make([]uint8, length)
+	for i := range buf {
+		buf[i] = p.Peek(src + i)
+	}
+	for i, value := range buf {
+		p.Poke(dest+i, value)
+	}
+}
+
+// Memset - set length bytes from dest to value
+func (p *pixelBuffer) Memset(dest int, value uint8, length int) {
+	for i := 0; i < length; i++ {
+		p.Poke(dest+i, value)
+	}
+}
+
+// peekPixels - 2 pixels packed into a byte
+func peekPixels(pix []uint8, offset int) uint8 {
+	i := offset * 2
+	if i+1 >= len(pix) {
+		return 0
+	}
+	return pix[i]&0x0f | pix[i+1]<<4
+}
+
+// pokePixels - unpacks a byte into 2 pixels
+func pokePixels(pix []uint8, offset int, value uint8) {
+	i := offset * 2
+	if i+1 >= len(pix) {
+		return
+	}
+	pix[i] = value & 0x0f
+	pix[i+1] = value >> 4
+}
+
+func peekBytes(b []uint8, offset int) uint8 {
+	if offset >= len(b) {
+		return 0
+	}
+	return b[offset]
+}
+
+func pokeBytes(b []uint8, offset int, value uint8) {
+	if offset < len(b) {
+		b[offset] = value
+	}
+}
+
+// peekDrawState - draw state is read from the pixel buffer
+func (p *pixelBuffer) peekDrawState(addr int) uint8 {
+	if addr < _memClip {
+		return p.peekPalette(addr)
+	}
+	cursor := charToPixel(p.textCursor)
+	switch addr {
+	case _memClip, _memClip + 1, _memClip + 2, _memClip + 3:
+		return p.clipBytes[addr-_memClip]
+	case _memColor:
+		return uint8(p.fgColor)
+	case _memCursor:
+		return uint8(cursor.x)
+	case _memCursor + 1:
+		return uint8(cursor.y)
+	case _memCamera:
+		return uint8(p.camera.x)
+	case _memCamera + 1:
+		return uint8(p.camera.x >> 8)
+	case _memCamera + 2:
+		return uint8(p.camera.y)
+	case _memCamera + 3:
+		return uint8(p.camera.y >> 8)
+	case _memFillp:
+		return uint8(p.fillPattern)
+	case _memFillp + 1:
+		return uint8(p.fillPattern >> 8)
+	case _memFillp + 2:
+		if p.fillTransparent {
+			return 1
+		}
+		return 0
+	}
+	return _console.ram[addr]
+}
+
+// pokeDrawState - draw state is written to the pixel buffer
+func (p *pixelBuffer) pokeDrawState(addr int, value uint8) {
+	if addr < _memClip {
+		p.pokePalette(addr, value)
+		return
+	}
+	cursor := charToPixel(p.textCursor)
+	switch addr {
+	case _memClip, _memClip + 1, _memClip + 2, _memClip + 3:
+		// the rect is rebuilt from all 4 bytes as it may be empty part way through writing them
+		p.clipBytes[addr-_memClip] = value
+		b := p.clipBytes
+		p.clipRect = image.Rect(int(b[0]), int(b[1]), int(b[2]), int(b[3])).Canon().Intersect(p.psRect)
+	case _memColor:
+		p.fgColor = ColorID(value)
+	case _memCursor:
+		cursor.x = int(value)
+		p.textCursor = pixelToChar(cursor)
+	case _memCursor + 1:
+		cursor.y = int(value)
+		p.textCursor = pixelToChar(cursor)
+	case _memCamera:
+		p.camera.x = int(int16(uint16(p.camera.x)&0xff00 | uint16(value)))
+	case _memCamera + 1:
+		p.camera.x = int(int16(uint16(p.camera.x)&0x00ff | uint16(value)<<8))
+	case _memCamera + 2:
+		p.camera.y = int(int16(uint16(p.camera.y)&0xff00 | uint16(value)))
+	case _memCamera + 3:
+		p.camera.y = int(int16(uint16(p.camera.y)&0x00ff | uint16(value)<<8))
+	case _memFillp:
+		p.fillPattern = p.fillPattern&0xff00 | uint16(value)
+	case _memFillp + 1:
+		p.fillPattern = p.fillPattern&0x00ff | uint16(value)<<8
+	case _memFillp + 2:
+		p.fillTransparent = value&1 != 0
+	}
+}
+
+// peekPalette - reads the first 16 entries of the draw & screen palettes
+// screen palette values from 128 are the colors after the first 16
+// entries past the end of a smaller palette read as 0
+func (p *pixelBuffer) peekPalette(addr int) uint8 {
+	if addr >= _memScreenPal {
+		if addr-_memScreenPal >= len(p.screenPal) {
+			return 0
+		}
+		c := p.screenPal[addr-_memScreenPal]
+		if c >= TOTAL_COLORS && len(p.screenPal) <= _secretColors {
+			return uint8(c-TOTAL_COLORS) + _secretColors
+		}
+		return uint8(c)
+	}
+	n := addr - _memDrawPal
+	if n >= len(p.drawPal) {
+		return 0
+	}
+	value := uint8(p.drawPal[n])
+	if p.transparent[n] {
+		value |= 0x10
+	}
+	return value
+}
+
+// pokePalette - writes the first 16 entries of the draw & screen palettes
+// entries past the end of a smaller palette are ignored by Pal & Palt
+func (p *pixelBuffer) pokePalette(addr int, value uint8) {
+	if addr >= _memScreenPal {
+		p.Pal(ColorID(addr-_memScreenPal), ColorID(value&0x8f), PAL_SCREEN)
+		return
+	}
+	n := ColorID(addr - _memDrawPal)
+	p.Pal(n, ColorID(value&0x0f))
+	p.Palt(n, value&0x10 != 0)
+}
+
+/*
+	Sfx are stored in the pico8 format
+
+	32 notes of 16 bits, low byte first
+		bits 0-5 pitch, 6-8 waveform, 9-11 volume, 12-14 effect
+	byte 64 editor mode (unused)
+	byte 65 speed
+	byte 66 loop start
+	byte 67 loop end
+
+	Music patterns are 4 bytes, one per channel
+		bits 0-5 sfx, bit 6 channel disabled, bit 7 flag
+	flags on channels 0-2 are loop start, loop end & stop
+*/
+
+func encodeNote(note Note) uint16 {
+	return uint16(note.Pitch&0x3f) |
+		uint16(note.Waveform&0x07)<<6 |
+		uint16(note.Volume&0x07)<<9 |
+		uint16(note.Effect&0x07)<<12
+}
+
+func decodeNote(v uint16) Note {
+	return Note{
+		Pitch:    uint8(v & 0x3f),
+		Waveform: uint8(v>>6) & 0x07,
+		Volume:   uint8(v>>9) & 0x07,
+		Effect:   uint8(v>>12) & 0x07,
+	}
+}
+
+// sfxByte - byte at offset of sfx n in memory format
+func (s *synth) sfxByte(n, offset int) uint8 {
+	if n < 0 || n >= _sfxCount {
+		return 0
+	}
+	s.Lock()
+	defer s.Unlock()
+	sfx := &s.sfx[n]
+	switch {
+	case offset < _sfxNotes*2:
+		v := encodeNote(sfx.Notes[offset/2])
+		return uint8(v >> (8 * uint(offset%2)))
+	case offset == 65:
+		return sfx.Speed
+	case offset == 66:
+		return sfx.LoopStart
+	case offset == 67:
+		return sfx.LoopEnd
+	}
+	return 0
+}
+
+// setSfxByte - sets byte at offset of sfx n in memory format
+func (s *synth) setSfxByte(n, offset int, value uint8) {
+	if n < 0 || n >= _sfxCount {
+		return
+	}
+	s.Lock()
+	defer s.Unlock()
+	sfx := &s.sfx[n]
+	switch {
+	case offset < _sfxNotes*2:
+		v := encodeNote(sfx.Notes[offset/2])
+		if offset%2 == 0 {
+			v = v&0xff00 | uint16(value)
+		} else {
+			v = v&0x00ff | uint16(value)<<8
+		}
+		sfx.Notes[offset/2] = decodeNote(v)
+	case offset == 65:
+		sfx.Speed = value
+	case offset == 66:
+		sfx.LoopStart = value
+	case offset == 67:
+		sfx.LoopEnd = value
+	}
+}
+
+// musicByte - byte for channel of music pattern n in memory format
+func (s *synth) musicByte(n, channel int) uint8 {
+	if n < 0 || n >= _musicCount {
+		return 0
+	}
+	s.Lock()
+	defer s.Unlock()
+	pattern := s.music[n]
+	v := uint8(0x40)
+	if pattern.Sfx[channel] >= 0 {
+		v = uint8(pattern.Sfx[channel]) & 0x3f
+	}
+	if (channel == 0 && pattern.LoopStart) ||
+		(channel == 1 && pattern.LoopEnd) ||
+		(channel == 2 && pattern.Stop) {
+		v |= 0x80
+	}
+	return v
+}
+
+// setMusicByte - sets byte for channel of music pattern n in memory format
+func (s *synth) setMusicByte(n, channel int, value uint8) {
+	if n < 0 || n >= _musicCount {
+		return
+	}
+	s.Lock()
+	defer s.Unlock()
+	pattern := &s.music[n]
+	if value&0x40 != 0 {
+		pattern.Sfx[channel] = -1
+	} else {
+		pattern.Sfx[channel] = int(value & 0x3f)
+	}
+	flag := value&0x80 != 0
+	switch channel {
+	case 0:
+		pattern.LoopStart = flag
+	case 1:
+		pattern.LoopEnd = flag
+	case 2:
+		pattern.Stop = flag
+	}
+}
@@ -0,0 +1,203 @@
+package console
+
+import (
+	"image"
+	"testing"
+)
+
+func TestPeekPokeScreen(t *testing.T) {
+	if err := InitHeadless(PICO8); err != nil {
+		t.Fatalf("Failed to init console: %s", err)
+	}
+	pb := _console.pb
+	pb.Cls(0)
+
+	// address 0 of screen is the top left pair of pixels
+	pb.PSet(0, 0, 7)
+	pb.PSet(1, 0, 8)
+	if v := pb.Peek(MEM_SCREEN); v != 0x87 {
+		t.Errorf("Expected screen byte 0x87 but got %#x", v)
+	}
+
+	// second row starts 64 bytes in
+	pb.Poke(MEM_SCREEN+64, 0xc3)
+	if pb.PGet(0, 1) != 3 || pb.PGet(1, 1) != 12 {
+		t.Errorf("Expected pixels 3 & 12 but got %d & %d", pb.PGet(0, 1), pb.PGet(1, 1))
+	}
+
+	pb.Memset(MEM_SCREEN, 0x11, 0x2000)
+	if pb.PGet(127, 127) != 1 {
+		t.Errorf("Expected memset to fill screen but got %d", pb.PGet(127, 127))
+	}
+}
+
+func TestPeekPokeSpritesMapFlags(t *testing.T) {
+	if err := InitHeadless(PICO8); err != nil {
+		t.Fatalf("Failed to init console: %s", err)
+	}
+	pb := _console.pb
+
+	// address 0 is no longer ignored
+	pb.Poke(0, 0x21)
+	sprites := _console.sprites[userSpriteBank1]
+	if sprites.Pix[0] != 1 || sprites.Pix[1] != 2 {
+		t.Errorf("Expected sprite pixels 1 & 2 but got %d & %d", sprites.Pix[0], sprites.Pix[1])
+	}
+	if pb.Peek(0) != 0x21 {
+		t.Errorf("Expected sprite byte 0x21 but got %#x", pb.Peek(0))
+	}
+
+	pb.MSet(3, 2, 42)
+	if v := pb.Peek(MEM_MAP + 2*128 + 3); v != 42 {
+		t.Errorf("Expected map byte 42 but got %d", v)
+	}
+	pb.Poke(MEM_MAP+5, 9)
+	if pb.MGet(5, 0) != 9 {
+		t.Errorf("Expected map cell 9 but got %d", pb.MGet(5, 0))
+	}
+
+	pb.FSet(4, 1, true)
+	if v := pb.Peek(MEM_SPRITE_FLAGS + 4); v != 0x02 {
+		t.Errorf("Expected sprite flags 0x02 but got %#x", v)
+	}
+}
+
+func TestPeekPokeAudio(t *testing.T) {
+	if err := InitHeadless(PICO8); err != nil {
+		t.Fatalf("Failed to init console: %s", err)
+	}
+	pb := _console.pb
+
+	sfx := SfxPattern{Speed: 16, LoopStart: 2, LoopEnd: 8}
+	sfx.Notes[1] = Note{Pitch: 33, Waveform: WAVE_SQUARE, Volume: 5, Effect: FX_VIBRATO}
+	if err := _console.SetSfx(3, sfx); err != nil {
+		t.Fatalf("Failed to set sfx: %s", err)
+	}
+
+	addr := MEM_SFX + 3*_sfxBytes
+	if v := pb.Peek2(addr + 2); v != int(encodeNote(sfx.Notes[1])) {
+		t.Errorf("Expected note %#x but got %#x", encodeNote(sfx.Notes[1]), v)
+	}
+	if pb.Peek(addr+65) != 16 || pb.Peek(addr+66) != 2 || pb.Peek(addr+67) != 8 {
+		t.Errorf("Expected speed & loop 16, 2, 8")
+	}
+
+	// copying sfx memory copies the sfx
+	pb.Memcpy(MEM_SFX, addr, _sfxBytes)
+	if _console.synth.sfx[0] != sfx {
+		t.Errorf("Expected sfx 0 to be a copy of sfx 3 but got %+v", _console.synth.sfx[0])
+	}
+
+	if err := _console.SetMusic(1, MusicPattern{Sfx: [4]int{3, -1, 5, -1}, LoopEnd: true}); err != nil {
+		t.Fatalf("Failed to set music: %s", err)
+	}
+	if v := pb.Peek4(MEM_MUSIC + 4); uint32(v) != 0x4005c003 {
+		t.Errorf("Expected music bytes 0x4005c003 but got %#x", uint32(v))
+	}
+	pb.Poke(MEM_MUSIC+4+3, 7)
+	if _console.synth.music[1].Sfx[3] != 7 {
+		t.Errorf("Expected music channel 3 sfx 7 but got %d", _console.synth.music[1].Sfx[3])
+	}
+}
+
+func TestPeekPokeDrawState(t *testing.T) {
+	if err := InitHeadless(PICO8); err != nil {
+		t.Fatalf("Failed to init console: %s", err)
+	}
+	pb := _console.pb
+
+	pb.Camera(-3, 260)
+	if pb.Peek2(_memCamera) != -3 || pb.Peek2(_memCamera+2) != 260 {
+		t.Errorf("Expected camera -3, 260 but got %d, %d", pb.Peek2(_memCamera), pb.Peek2(_memCamera+2))
+	}
+	pb.Poke2(_memCamera, 10)
+	if pb.camera.x != 10 {
+		t.Errorf("Expected camera x 10 but got %d", pb.camera.x)
+	}
+
+	pb.Clip(8, 16, 32, 32)
+	pb.Poke(_memClip+2, 20)
+	if pb.clipRect != image.Rect(8, 16, 20, 48) {
+		t.Errorf("Expected clip rect (8,16)-(20,48) but got %v", pb.clipRect)
+	}
+
+	// the rect may be empty part way through poking its bytes
+	pb.Clip(0, 0, 20, 20)
+	for i, v := range []uint8{50, 50, 100, 100} {
+		pb.Poke(_memClip+i, v)
+	}
+	if pb.clipRect != image.Rect(50, 50, 100, 100) {
+		t.Errorf("Expected clip rect (50,50)-(100,100) but got %v", pb.clipRect)
+	}
+	pb.Clip(0, 0, 20, 20)
+	pb.Poke2(_memClip, 0x3c3c)
+	pb.Poke2(_memClip+2, 0x4650)
+	if pb.clipRect != image.Rect(60, 60, 80, 70) || pb.Peek(_memClip) != 60 {
+		t.Errorf("Expected clip rect (60,60)-(80,70) but got %v", pb.clipRect)
+	}
+
+	pb.Poke(_memColor, 9)
+	if pb.fgColor != 9 {
+		t.Errorf("Expected color 9 but got %d", pb.fgColor)
+	}
+}
+
+func TestPeekPokeSmallPalette(t *testing.T) {
+	if err := InitHeadless(PICO8); err != nil {
+		t.Fatalf("Failed to init console: %s", err)
+	}
+	pb := _console.pb
+	if err := pb.SetPalette(0x000000, 0xffffff); err != nil {
+		t.Fatalf("Failed to set palette: %s", err)
+	}
+
+	// entries past the 2 colors read as 0 and writes to them are ignored
+	for addr := _memDrawPal; addr < _memClip; addr++ {
+		pb.Poke(addr, 0x01)
+		n := addr - _memDrawPal
+		if addr >= _memScreenPal {
+			n = addr - _memScreenPal
+		}
+		if n >= 2 {
+			if v := pb.Peek(addr); v != 0 {
+				t.Errorf("Expected palette entry %#x outside palette to be 0 but got %#x", addr, v)
+			}
+		}
+	}
+	if pb.drawPal[0] != 1 || pb.screenPal[1] != 1 {
+		t.Errorf("Expected poked palette entries inside palette but got %d & %d", pb.drawPal[0], pb.screenPal[1])
+	}
+}
+
+func TestPeekPokeRAM(t *testing.T) {
+	if err := InitHeadless(PICO8); err != nil {
+		t.Fatalf("Failed to init console: %s", err)
+	}
+	pb := _console.pb
+
+	pb.Poke4(MEM_USER_DATA, FixFromFloat(-1.5))
+	if v := pb.Peek4(MEM_USER_DATA); v != FixFromFloat(-1.5) {
+		t.Errorf("Expected -1.5 but got %s", v)
+	}
+	pb.Poke2(MEM_UPPER, -2)
+	if v := pb.Peek2(MEM_UPPER); v != -2 {
+		t.Errorf("Expected -2 but got %d", v)
+	}
+
+	// overlapping copy
+	for i := 0; i < 4; i++ {
+		pb.Poke(MEM_UPPER+i, uint8(i+1))
+	}
+	pb.Memcpy(MEM_UPPER+1, MEM_UPPER, 4)
+	for i, expected := range []uint8{1, 1, 2, 3, 4} {
+		if v := pb.Peek(MEM_UPPER + i); v != expected {
+			t.Errorf("Memcpy: expected %d at %d but got %d", expected, i, v)
+		}
+	}
+
+	// out of range is ignored
+	pb.Poke(MEM_SIZE, 1)
+	if pb.Peek(-1) != 0 || pb.Peek(MEM_SIZE) != 0 {
+		t.Errorf("Expected out of range peek to return 0")
+	}
+}
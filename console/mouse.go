@@ -0,0 +1,47 @@
+package console
+
+// Mouse button bits returned by Stat(STAT_MOUSE_BUTTONS)
+const (
+	MOUSE_LEFT = 1 << iota
+	MOUSE_RIGHT
+	MOUSE_MIDDLE
+)
+
+// mouseState - state of the mouse sampled once per frame
+type mouseState struct {
+	x       int
+	y       int
+	buttons int
+	wheel   int
+}
+
+// sample - stores mouse position already converted to console pixels
+// isPressed is called with each of the MOUSE_ button bits
+func (m *mouseState) sample(x, y int, isPressed func(button int) bool, wheelY float64) {
+	m.x = x
+	m.y = y
+	m.buttons = 0
+	for button := MOUSE_LEFT; button <= MOUSE_MIDDLE; button <<= 1 {
+		if isPressed(button) {
+			m.buttons |= button
+		}
+	}
+	switch {
+	case wheelY > 0:
+		m.wheel = 1
+	case wheelY < 0:
+		m.wheel = -1
+	default:
+		m.wheel = 0
+	}
+}
+
+// windowToPixel - transforms window coords to pixel buffer coords
+func (p *pixelBuffer) windowToPixel(x, y int) (int, int) {
+	if p.renderRect.Dx() == 0 || p.renderRect.Dy() == 0 {
+		return x, y
+	}
+	px := (x - p.renderRect.Min.X) * p.psRect.Dx() / p.renderRect.Dx()
+	py := (y - p.renderRect.Min.Y) * p.psRect.Dy() / p.renderRect.Dy()
+	return px, py
+}
@@ -0,0 +1,40 @@
+package console
+
+import (
+	"image"
+	"testing"
+)
+
+func TestWindowToPixel(t *testing.T) {
+	Init(PICO8)
+	pb := _console.pb
+
+	// console rendered at 4x scale with an offset
+	pb.renderRect = image.Rect(32, 16, 32+128*4, 16+128*4)
+	defer func() { pb.renderRect = pb.psRect }()
+
+	x, y := pb.windowToPixel(32+10*4, 16+20*4+3)
+	if x != 10 || y != 20 {
+		t.Errorf("Expected pixel pos: %d,%d got: %d,%d", 10, 20, x, y)
+	}
+}
+
+func TestMouseStat(t *testing.T) {
+	Init(PICO8)
+
+	pressed := func(button int) bool { return button == MOUSE_RIGHT }
+	_console.mouse.sample(12, 34, pressed, -2.5)
+
+	tests := map[int]int{
+		STAT_MOUSE_X:       12,
+		STAT_MOUSE_Y:       34,
+		STAT_MOUSE_BUTTONS: MOUSE_RIGHT,
+		STAT_MOUSE_WHEEL:   -1,
+		0:                  0,
+	}
+	for n, expected := range tests {
+		if got := _console.Stat(n); got != expected {
+			t.Errorf("For stat: %d expected: %d got: %d", n, expected, got)
+		}
+	}
+}
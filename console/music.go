@@ -0,0 +1,222 @@
+package console
+
+import (
+	"fmt"
+)
+
+const _musicCount = 64 // number of music patterns
+
+// MusicPattern - up to 4 sfx played together, one per channel
+type MusicPattern struct {
+	Sfx       [_audioChannels]int // sfx played on each channel, -1 for none
+	LoopStart bool                // LoopEnd jumps back to this pattern
+	LoopEnd   bool                // after this pattern jump back to the loop start
+	Stop      bool                // stop music after this pattern
+}
+
+// NewMusicPattern - returns a pattern playing sfx on channels 0-3, unused channels are silent
+func NewMusicPattern(sfx ...int) MusicPattern {
+	pattern := MusicPattern{}
+	for i := range pattern.Sfx {
+		pattern.Sfx[i] = -1
+		if i < len(sfx) {
+			pattern.Sfx[i] = sfx[i]
+		}
+	}
+	return pattern
+}
+
+// isEmpty - true if pattern plays no sfx
+func (m MusicPattern) isEmpty() bool {
+	for _, n := range m.Sfx {
+		if n >= 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// musicState - playback state of the music sequencer
+type musicState struct {
+	pattern  int     // pattern playing, -1 when stopped
+	pos      int     // samples played of current pattern
+	length   int     // samples in current pattern
+	reserved int     // mask of channels sfx should avoid
+	gain     float64 // volume of music channels 0..1
+	gainStep float64 // change in gain per sample while fading
+}
+
+// Music - plays music from pattern n fading in over fadeMs
+// channels in channelMask are reserved for music, n = -1 stops music fading out over fadeMs
+func (c *console) Music(n, fadeMs, channelMask int) {
+	if c.synth == nil {
+		return
+	}
+	c.synth.playMusic(n, fadeMs, channelMask)
+}
+
+// SetMusic - defines music pattern n
+func (c *console) SetMusic(n int, pattern MusicPattern) error {
+	if c.synth == nil {
+		return fmt.Errorf("Error setting music - audio not initialised")
+	}
+	return c.synth.setMusic(n, pattern)
+}
+
+func (s *synth) setMusic(n int, pattern MusicPattern) error {
+	if n < 0 || n >= _musicCount {
+		return fmt.Errorf("Error setting music - number outside range: %d", n)
+	}
+	for _, sfx := range pattern.Sfx {
+		if sfx >= _sfxCount {
+			return fmt.Errorf("Error setting music - sfx outside range: %d", sfx)
+		}
+	}
+	s.Lock()
+	defer s.Unlock()
+	s.music[n] = pattern
+	return nil
+}
+
+func (s *synth) playMusic(n, fadeMs, channelMask int) {
+	s.Lock()
+	defer s.Unlock()
+
+	fadeSamples := fadeMs * _sampleRate / 1000
+
+	if n < 0 {
+		if s.musicState.pattern < 0 || fadeSamples <= 0 {
+			s.stopMusic()
+			return
+		}
+		s.musicState.gainStep = -1 / float64(fadeSamples)
+		return
+	}
+	if n >= _musicCount {
+		return
+	}
+
+	s.musicState.reserved = channelMask
+	s.musicState.gain = 1
+	s.musicState.gainStep = 0
+	if fadeSamples > 0 {
+		s.musicState.gain = 0
+		s.musicState.gainStep = 1 / float64(fadeSamples)
+	}
+	s.startPattern(n)
+}
+
+// startPattern - starts the sfx of pattern n on their channels
+func (s *synth) startPattern(n int) {
+	pattern := s.music[n]
+	if pattern.isEmpty() {
+		s.stopMusic()
+		return
+	}
+	s.musicState.pattern = n
+	s.musicState.pos = 0
+	s.musicState.length = s.patternLength(pattern)
+	for i, sfx := range pattern.Sfx {
+		if sfx >= 0 {
+			s.startSfx(i, sfx, 0)
+			s.channels[i].music = true
+		} else if s.channels[i].music {
+			s.channels[i].sfx = -1
+		}
+	}
+}
+
+// patternLength - samples in pattern, timed by the first sfx that doesn't loop
+func (s *synth) patternLength(pattern MusicPattern) int {
+	ticks := 0
+	for _, n := range pattern.Sfx {
+		if n < 0 {
+			continue
+		}
+		sfx := s.sfx[n]
+		speed := int(sfx.Speed)
+		if speed < 1 {
+			speed = 1
+		}
+		if sfx.LoopEnd <= sfx.LoopStart {
+			ticks = speed * _sfxNotes
+			break
+		}
+		if ticks == 0 {
+			// all channels loop, time by the loop end of the first
+			ticks = speed * int(sfx.LoopEnd)
+		}
+	}
+	return ticks * _samplesPerTick
+}
+
+// nextPattern - pattern to play after the current one, -1 to stop
+func (s *synth) nextPattern() int {
+	n := s.musicState.pattern
+	pattern := s.music[n]
+	switch {
+	case pattern.Stop:
+		return -1
+	case pattern.LoopEnd:
+		for i := n; i >= 0; i-- {
+			if s.music[i].LoopStart {
+				return i
+			}
+		}
+		return 0
+	case n+1 >= _musicCount:
+		return -1
+	}
+	return n + 1
+}
+
+// updateMusic - advances music by samples, called once per console frame
+func (s *synth) updateMusic(samples int) {
+	s.Lock()
+	defer s.Unlock()
+
+	if s.musicState.pattern < 0 {
+		return
+	}
+	s.musicState.pos += samples
+	if s.musicState.pos < s.musicState.length {
+		return
+	}
+	pos := s.musicState.pos - s.musicState.length
+	next := s.nextPattern()
+	if next < 0 {
+		s.stopMusic()
+		return
+	}
+	s.startPattern(next)
+	s.musicState.pos = pos
+}
+
+// stopMusic - stops all music channels
+func (s *synth) stopMusic() {
+	for i := range s.channels {
+		if s.channels[i].music {
+			s.channels[i].sfx = -1
+			s.channels[i].music = false
+		}
+	}
+	s.musicState = musicState{
+		pattern: -1,
+		gain:    1,
+	}
+}
+
+// fadeMusic - applies fade to music gain, called once per sample
+func (s *synth) fadeMusic() {
+	if s.musicState.gainStep == 0 {
+		return
+	}
+	s.musicState.gain += s.musicState.gainStep
+	switch {
+	case s.musicState.gain >= 1:
+		s.musicState.gain = 1
+		s.musicState.gainStep = 0
+	case s.musicState.gain <= 0:
+		s.stopMusic()
+	}
+}
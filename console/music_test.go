@@ -0,0 +1,127 @@
+package console
+
+import (
+	"testing"
+)
+
+const testFrameSamples = _sampleRate / _fps
+
+// newTestMusicSynth - synth with 3 patterns of a 1 tick per note sfx, pattern 1 loops back to 0
+func newTestMusicSynth() *synth {
+	s := newSynth()
+	s.setSfx(0, newTestSfx(24, 1))
+	s.setSfx(1, newTestSfx(36, 1))
+
+	p0 := NewMusicPattern(0, 1)
+	p0.LoopStart = true
+	p1 := NewMusicPattern(1)
+	p1.LoopEnd = true
+	s.setMusic(0, p0)
+	s.setMusic(1, p1)
+	return s
+}
+
+// framesPerPattern - frames needed to play a 32 note, 1 tick per note sfx
+func framesPerPattern() int {
+	samples := _sfxNotes * _samplesPerTick
+	return (samples + testFrameSamples - 1) / testFrameSamples
+}
+
+func TestMusicSequence(t *testing.T) {
+	s := newTestMusicSynth()
+	s.playMusic(0, 0, 0)
+
+	if s.musicState.pattern != 0 {
+		t.Fatalf("Expected pattern: %d got: %d", 0, s.musicState.pattern)
+	}
+	if s.channels[0].sfx != 0 || s.channels[1].sfx != 1 || s.channels[2].sfx != -1 {
+		t.Fatalf("Expected pattern sfx on channels got: %d %d %d", s.channels[0].sfx, s.channels[1].sfx, s.channels[2].sfx)
+	}
+
+	for i := 0; i < framesPerPattern(); i++ {
+		s.updateMusic(testFrameSamples)
+	}
+	if s.musicState.pattern != 1 {
+		t.Fatalf("Expected pattern: %d got: %d", 1, s.musicState.pattern)
+	}
+	// channel 1 is not used by pattern 1 so is stopped
+	if s.channels[0].sfx != 1 || s.channels[1].sfx != -1 {
+		t.Errorf("Expected pattern 1 sfx on channels got: %d %d", s.channels[0].sfx, s.channels[1].sfx)
+	}
+
+	// loop end jumps back to loop start
+	for i := 0; i < framesPerPattern(); i++ {
+		s.updateMusic(testFrameSamples)
+	}
+	if s.musicState.pattern != 0 {
+		t.Errorf("Expected music to loop to pattern: %d got: %d", 0, s.musicState.pattern)
+	}
+}
+
+func TestMusicStopFlag(t *testing.T) {
+	s := newSynth()
+	s.setSfx(0, newTestSfx(24, 1))
+	p0 := NewMusicPattern(0)
+	p0.Stop = true
+	s.setMusic(0, p0)
+	s.setMusic(1, NewMusicPattern(0))
+
+	s.playMusic(0, 0, 0)
+	for i := 0; i < framesPerPattern(); i++ {
+		s.updateMusic(testFrameSamples)
+	}
+	if s.musicState.pattern != -1 {
+		t.Errorf("Expected music to stop got pattern: %d", s.musicState.pattern)
+	}
+	if s.channels[0].sfx != -1 {
+		t.Errorf("Expected music channel to stop")
+	}
+}
+
+func TestMusicFade(t *testing.T) {
+	s := newTestMusicSynth()
+	s.playMusic(0, 100, 0)
+	if s.musicState.gain != 0 {
+		t.Fatalf("Expected music to start silent got gain: %f", s.musicState.gain)
+	}
+
+	buf := make([]int16, _sampleRate/10)
+	s.render(buf)
+	if s.musicState.gain != 1 {
+		t.Errorf("Expected music to fade in got gain: %f", s.musicState.gain)
+	}
+
+	s.playMusic(-1, 100, 0)
+	s.render(buf)
+	if s.musicState.pattern != -1 {
+		t.Errorf("Expected music to stop after fading out")
+	}
+}
+
+func TestMusicReservedChannels(t *testing.T) {
+	s := newTestMusicSynth()
+	s.setSfx(5, newTestSfx(40, 8))
+
+	// reserve channels 0-2 for music
+	s.playMusic(0, 0, 7)
+	s.play(5, -1, 0)
+	if s.channels[3].sfx != 5 {
+		t.Errorf("Expected sfx to play on channel %d got: %d", 3, s.channels[3].sfx)
+	}
+}
+
+func TestMusicSkippedFrames(t *testing.T) {
+	if err := InitHeadless(PICO8); err != nil {
+		t.Fatalf("Failed to init headless console: %s", err)
+	}
+	_console.synth = newTestMusicSynth()
+	_console.synth.playMusic(0, 0, 0)
+
+	// dropped frames still advance the music
+	for i := 0; i < framesPerPattern(); i++ {
+		_console.skipFrame()
+	}
+	if _console.synth.musicState.pattern != 1 {
+		t.Errorf("Expected pattern: %d got: %d", 1, _console.synth.musicState.pattern)
+	}
+}
@@ -2,7 +2,6 @@ package console
 
 import (
 	"fmt"
-	"image"
 	"image/color"
 )
 
@@ -26,6 +25,30 @@ const (
 	PICO8_PEACH
 )
 
+// PICO8 - secret colors, only displayed by mapping them in the screen palette
+const (
+	PICO8_BROWNISH_BLACK ColorID = iota + TOTAL_COLORS
+	PICO8_DARKER_BLUE
+	PICO8_DARKER_PURPLE
+	PICO8_BLUE_GREEN
+	PICO8_DARK_BROWN
+	PICO8_DARKER_GRAY
+	PICO8_MEDIUM_GRAY
+	PICO8_LIGHT_YELLOW
+	PICO8_DARK_RED
+	PICO8_DARK_ORANGE
+	PICO8_LIME_GREEN
+	PICO8_MEDIUM_GREEN
+	PICO8_TRUE_BLUE
+	PICO8_MAUVE
+	PICO8_DARK_PEACH
+	PICO8_LIGHT_PEACH
+)
+
+// _secretColors - screen palette values from 128 address the colors after the first 16
+// like pico8 does, unless the palette is large enough to hold them
+const _secretColors = 128
+
 // TIC80 - colors
 const (
 	TIC80_BLACK ColorID = iota
@@ -86,6 +109,12 @@ const (
 	C64_LIGHT_GREY
 )
 
+// Pal - palette modes
+const (
+	PAL_DRAW   = 0 // remap colors as they are drawn
+	PAL_SCREEN = 1 // remap colors as the screen is displayed
+)
+
 type rgba struct {
 	R uint8
 	G uint8
@@ -98,6 +127,7 @@ type palette struct {
 	rgbaMap        map[uint32]ColorID
 	colors         []color.Color
 	originalColors []color.Color
+	secretColors   bool // colors after the first 16 are only displayed by the screen palette
 }
 
 func newPalette(consoleType ConsoleType) *palette {
@@ -118,8 +148,8 @@ func newPico8Palette() *palette {
 
 	p := &palette{}
 	// set colours in palette
-	p.colors = make([]color.Color, TOTAL_COLORS)
-	p.originalColors = make([]color.Color, TOTAL_COLORS)
+	p.colors = make([]color.Color, PICO8_LIGHT_PEACH+1)
+	p.originalColors = make([]color.Color, PICO8_LIGHT_PEACH+1)
 	p.originalColors[PICO8_BLACK] = color.RGBA{R: 0, G: 0, B: 0, A: 255}
 	p.originalColors[PICO8_DARK_BLUE] = color.RGBA{R: 29, G: 43, B: 83, A: 255}
 	p.originalColors[PICO8_DARK_PURPLE] = color.RGBA{R: 126, G: 37, B: 83, A: 255}
@@ -136,6 +166,23 @@ func newPico8Palette() *palette {
 	p.originalColors[PICO8_INDIGO] = color.RGBA{R: 131, G: 118, B: 156, A: 255}
 	p.originalColors[PICO8_PINK] = color.RGBA{R: 255, G: 119, B: 168, A: 255}
 	p.originalColors[PICO8_PEACH] = color.RGBA{R: 255, G: 204, B: 170, A: 255}
+	p.originalColors[PICO8_BROWNISH_BLACK] = color.RGBA{R: 41, G: 24, B: 20, A: 255}
+	p.originalColors[PICO8_DARKER_BLUE] = color.RGBA{R: 17, G: 29, B: 53, A: 255}
+	p.originalColors[PICO8_DARKER_PURPLE] = color.RGBA{R: 66, G: 33, B: 54, A: 255}
+	p.originalColors[PICO8_BLUE_GREEN] = color.RGBA{R: 18, G: 83, B: 89, A: 255}
+	p.originalColors[PICO8_DARK_BROWN] = color.RGBA{R: 116, G: 47, B: 41, A: 255}
+	p.originalColors[PICO8_DARKER_GRAY] = color.RGBA{R: 73, G: 51, B: 59, A: 255}
+	p.originalColors[PICO8_MEDIUM_GRAY] = color.RGBA{R: 162, G: 136, B: 121, A: 255}
+	p.originalColors[PICO8_LIGHT_YELLOW] = color.RGBA{R: 243, G: 239, B: 125, A: 255}
+	p.originalColors[PICO8_DARK_RED] = color.RGBA{R: 190, G: 18, B: 80, A: 255}
+	p.originalColors[PICO8_DARK_ORANGE] = color.RGBA{R: 255, G: 108, B: 36, A: 255}
+	p.originalColors[PICO8_LIME_GREEN] = color.RGBA{R: 168, G: 231, B: 46, A: 255}
+	p.originalColors[PICO8_MEDIUM_GREEN] = color.RGBA{R: 0, G: 181, B: 67, A: 255}
+	p.originalColors[PICO8_TRUE_BLUE] = color.RGBA{R: 6, G: 90, B: 181, A: 255}
+	p.originalColors[PICO8_MAUVE] = color.RGBA{R: 117, G: 70, B: 101, A: 255}
+	p.originalColors[PICO8_DARK_PEACH] = color.RGBA{R: 255, G: 110, B: 89, A: 255}
+	p.originalColors[PICO8_LIGHT_PEACH] = color.RGBA{R: 255, G: 157, B: 129, A: 255}
+	p.secretColors = true
 
 	// copy to working colors
 	for i := range p.originalColors {
@@ -297,14 +344,10 @@ func (p *palette) GetColor(colorID ColorID) color.Color {
 	}
 	return p.colors[colorID]
 }
-func setSurfacePalette(palette Paletter, surface *image.Paletted) error {
-	surface.Palette = palette.GetColors()
-	return nil
-}
 
 func (p *palette) PaletteReset() {
 
-	for i, c := range _console.originalPalette.colors {
+	for i, c := range p.originalColors {
 		p.colors[i] = c
 	}
 	p.updateColorMaps()
@@ -319,6 +362,30 @@ func (p *palette) PaletteCopy() Paletter {
 	return p2
 }
 
+// SetPalette - replaces all colors with 0xRRGGBB values, up to MAX_COLORS
+func (p *palette) SetPalette(rgb ...uint32) error {
+	if len(rgb) == 0 || len(rgb) > MAX_COLORS {
+		return fmt.Errorf("Error setting palette - invalid number of colors: %d", len(rgb))
+	}
+	p.colors = make([]color.Color, len(rgb))
+	p.originalColors = make([]color.Color, len(rgb))
+	p.secretColors = false
+	for i, c := range rgb {
+		p.originalColors[i] = color.RGBA{R: uint8(c >> 16), G: uint8(c >> 8), B: uint8(c), A: 255}
+		p.colors[i] = p.originalColors[i]
+	}
+	p.updateColorMaps()
+	return nil
+}
+
+// drawColors - returns the colors that can be drawn, secret colors are excluded
+func (p *palette) drawColors() color.Palette {
+	if p.secretColors {
+		return p.originalColors[:TOTAL_COLORS]
+	}
+	return p.originalColors
+}
+
 func (p *palette) GetColors() []color.Color {
 	return p.colors
 }
@@ -11,9 +11,6 @@ import (
 	"golang.org/x/image/font"
 	"golang.org/x/image/math/f64"
 	"golang.org/x/image/math/fixed"
-
-	"github.com/hajimehoshi/ebiten"
-	"github.com/hajimehoshi/ebiten/ebitenutil"
 )
 
 type mode struct {
@@ -34,14 +31,23 @@ type pixelBuffer struct {
 	charRows     int
 	pixelSurface *image.Paletted // offscreen pixel buffer
 	rgbaPixels   []uint8
-	screen       *ebiten.Image
 	psRect       image.Rectangle // rect of pixelSurface
 	renderRect   image.Rectangle // rect on main window that pixelbuffer is rendered into
+	camera       pos             // offset subtracted from all drawing coords
+	clipRect     image.Rectangle // drawing is limited to this rect
+	clipBytes    [4]uint8        // clip rect as stored in memory, pokes rebuild the rect from it
+
+	fillPattern     uint16  // 4x4 pattern applied to shapes, 0 is solid
+	fillTransparent bool    // set bits of the pattern are not drawn
+	fillColor       ColorID // color of set bits of the pattern
+
+	drawPal     []ColorID // colors are remapped by the draw palette as they are drawn
+	screenPal   []ColorID // colors are remapped by the screen palette when displayed
+	transparent []bool    // sprite pixels of transparent colors are not drawn
 
 	// these are temp paletted images stored by size for reuse
-	copySpritesMap map[image.Rectangle]*image.Paletted
-	txSpritesMap   map[image.Rectangle]*image.Paletted
-	maskSpritesMap map[image.Rectangle]*image.Paletted
+	txSpritesMap       map[image.Rectangle]*image.Paletted
+	coverageSpritesMap map[image.Rectangle]*image.Alpha
 
 	// these are cached versions of previously transformed sprites
 	spriteCache map[spriteTx]spriteCached
@@ -50,9 +56,8 @@ type pixelBuffer struct {
 }
 
 type spriteTx struct {
-	number      int
-	width       int
-	height      int
+	bank        int
+	src         image.Rectangle // pixels on the sprite sheet
 	scaleWidth  int
 	scaleHeight int
 	rot         int
@@ -61,9 +66,9 @@ type spriteTx struct {
 }
 
 type spriteCached struct {
-	txImage   *image.Paletted
-	maskImage *image.Paletted
-	lastUsed  time.Time
+	txImage  *image.Paletted // color ids of the transformed sprite
+	coverage *image.Alpha    // pixels covered by the transformed sprite
+	lastUsed time.Time
 }
 
 type pos struct {
@@ -76,31 +81,23 @@ func newPixelBuffer(cfg Config) (*pixelBuffer, error) {
 
 	p.psRect = image.Rect(0, 0, cfg.ConsoleWidth, cfg.ConsoleHeight)
 	p.renderRect = image.Rect(0, 0, cfg.ConsoleWidth, cfg.ConsoleHeight)
+	p.setClipRect(p.psRect)
 
-	ps := image.NewPaletted(p.psRect, cfg.palette.colors)
+	// the surface holds color ids, the screen palette is applied when displayed
+	ps := image.NewPaletted(p.psRect, append(color.Palette{}, cfg.palette.originalColors...))
 
 	if ps == nil {
 		return nil, fmt.Errorf("Surface is nil")
 	}
 
 	p.palette = cfg.palette
-	p.r = make([]uint8, len(_console.palette.colorMap), len(_console.palette.colorMap))
-	p.g = make([]uint8, len(_console.palette.colorMap), len(_console.palette.colorMap))
-	p.b = make([]uint8, len(_console.palette.colorMap), len(_console.palette.colorMap))
-	p.a = make([]uint8, len(_console.palette.colorMap), len(_console.palette.colorMap))
+	p.r = make([]uint8, MAX_COLORS)
+	p.g = make([]uint8, MAX_COLORS)
+	p.b = make([]uint8, MAX_COLORS)
+	p.a = make([]uint8, MAX_COLORS)
 
 	p.spriteCache = make(map[spriteTx]spriteCached)
 
-	if err := setSurfacePalette(p.palette, ps); err != nil {
-		return nil, err
-	}
-
-	var err error
-	screen, err := ebiten.NewImageFromImage(ps, ebiten.FilterNearest)
-	if err != nil {
-		return nil, fmt.Errorf("Failed to create ebiten image %s", err)
-	}
-	p.screen = screen
 	p.pixelSurface = ps
 
 	p.textCursor.x = 0
@@ -113,9 +110,8 @@ func newPixelBuffer(cfg Config) (*pixelBuffer, error) {
 
 	p.rgbaPixels = make([]uint8, _console.Config.ConsoleWidth*_console.Config.ConsoleHeight*4)
 	// init temp sprite maps
-	p.copySpritesMap = make(map[image.Rectangle]*image.Paletted)
 	p.txSpritesMap = make(map[image.Rectangle]*image.Paletted)
-	p.maskSpritesMap = make(map[image.Rectangle]*image.Paletted)
+	p.coverageSpritesMap = make(map[image.Rectangle]*image.Alpha)
 
 	return p, nil
 }
@@ -141,7 +137,10 @@ func (p *pixelBuffer) Cls(colorID ...ColorID) {
 		p.bgColor = colorID[0]
 	}
 
-	bg := uint8(p.bgColor)
+	// clearing screen also resets the clipping rect
+	p.setClipRect(p.psRect)
+
+	bg := uint8(p.baseColor(p.bgColor))
 
 	// fill every pixel with same color
 	for i, _ := range p.pixelSurface.Pix {
@@ -176,24 +175,32 @@ func (p *pixelBuffer) Flip() error {
 
 	p.flipReady = false
 	// record frame
-	//_console.recorder.AddFrame(p.GetFrame(), p)
+	if _console.recorder != nil {
+		_console.recorder.AddFrame(p.GetFrame(), p.palette.colors)
+	}
 
 	// at end of frame delay start timing for next one
 	startFrame = time.Now()
 
-	p.copyIndexedToRGBA()
-
-	_console.screen.ReplacePixels(p.rgbaPixels)
-	if _console.showFPS {
-		ebitenutil.DebugPrint(_console.screen, fmt.Sprintf("FPS: %f", ebiten.CurrentFPS()))
+	// headless frames stay in the paletted image
+	if _console.headless {
+		return nil
 	}
 
+	p.copyIndexedToRGBA()
+	p.display()
+
 	return nil
 }
 
 // copyIndexedToRGBA - convert the paletted (indexed) image into a set of RGBA pixels for rendering on display
 func (p *pixelBuffer) copyIndexedToRGBA() {
-	for id, rgba := range _console.palette.colorMap {
+	// pixels outside the palette are displayed as color 0
+	for id := range p.r {
+		rgba, ok := _console.palette.colorMap[ColorID(id)]
+		if !ok {
+			rgba = _console.palette.colorMap[0]
+		}
 		p.r[id] = rgba.R
 		p.g[id] = rgba.G
 		p.b[id] = rgba.B
@@ -201,9 +208,7 @@ func (p *pixelBuffer) copyIndexedToRGBA() {
 	}
 
 	i := 0
-	paletteSize := uint8(len(p.r))
 	for _, palPix := range p.pixelSurface.Pix {
-		palPix = palPix % paletteSize
 		p.rgbaPixels[i] = p.r[palPix]
 		i++
 		p.rgbaPixels[i] = p.g[palPix]
@@ -281,11 +286,12 @@ func (p *pixelBuffer) printAtWithColor(str string, x, y int, colorID ColorID) {
 
 	if str != "" {
 		y += (_console.fontHeight / 2) + 1
-		col := _console.palette.colors[colorID]
-		point := fixed.Point26_6{X: fixed.Int26_6(x * 64), Y: fixed.Int26_6(y * 64)}
+		col := p.palette.originalColors[p.drawColor(colorID)]
+		cx, cy := x-p.camera.x, y-p.camera.y
+		point := fixed.Point26_6{X: fixed.Int26_6(cx * 64), Y: fixed.Int26_6(cy * 64)}
 
 		d := &font.Drawer{
-			Dst:  p.pixelSurface,
+			Dst:  p.clipSurface(),
 			Src:  image.NewUniform(col),
 			Face: _console.font,
 			Dot:  point,
@@ -313,6 +319,8 @@ func (p *pixelBuffer) Circle(x, y, r int, colorID ...ColorID) {
 // CircleWithColor - draw circle with color
 func (p *pixelBuffer) circleWithColor(x0, y0, r int, colorID ColorID) {
 	p.fgColor = colorID
+	x0 -= p.camera.x
+	y0 -= p.camera.y
 
 	x := 0
 	y := r
@@ -322,7 +330,7 @@ func (p *pixelBuffer) circleWithColor(x0, y0, r int, colorID ColorID) {
 	http://xiaohuiliucuriosity.blogspot.co.uk/2015/03/draw-circle-using-integer-arithmetic.html
 	*/
 
-	p.circlePoints(x0, y0, x, y)
+	p.circlePoints(x0, y0, x, y, colorID)
 	for x < y {
 		x++
 		if p0 < 0 {
@@ -331,32 +339,32 @@ func (p *pixelBuffer) circleWithColor(x0, y0, r int, colorID ColorID) {
 			y--
 			p0 += 2*(x-y) + 1
 		}
-		p.circlePoints(x0, y0, x, y)
+		p.circlePoints(x0, y0, x, y, colorID)
 	}
 
 }
 
-func (p *pixelBuffer) circlePoints(cx, cy, x, y int) {
+func (p *pixelBuffer) circlePoints(cx, cy, x, y int, colorID ColorID) {
 
 	if x == 0 {
-		p.PSet(cx, cy+y)
-		p.PSet(cx, cy-y)
-		p.PSet(cx+y, cy)
-		p.PSet(cx-y, cy)
+		p.setPixel(cx, cy+y, colorID)
+		p.setPixel(cx, cy-y, colorID)
+		p.setPixel(cx+y, cy, colorID)
+		p.setPixel(cx-y, cy, colorID)
 	} else if x == y {
-		p.PSet(cx+x, cy+y)
-		p.PSet(cx-x, cy+y)
-		p.PSet(cx+x, cy-y)
-		p.PSet(cx-x, cy-y)
+		p.setPixel(cx+x, cy+y, colorID)
+		p.setPixel(cx-x, cy+y, colorID)
+		p.setPixel(cx+x, cy-y, colorID)
+		p.setPixel(cx-x, cy-y, colorID)
 	} else if x < y {
-		p.PSet(cx+x, cy+y)
-		p.PSet(cx-x, cy+y)
-		p.PSet(cx+x, cy-y)
-		p.PSet(cx-x, cy-y)
-		p.PSet(cx+y, cy+x)
-		p.PSet(cx-y, cy+x)
-		p.PSet(cx+y, cy-x)
-		p.PSet(cx-y, cy-x)
+		p.setPixel(cx+x, cy+y, colorID)
+		p.setPixel(cx-x, cy+y, colorID)
+		p.setPixel(cx+x, cy-y, colorID)
+		p.setPixel(cx-x, cy-y, colorID)
+		p.setPixel(cx+y, cy+x, colorID)
+		p.setPixel(cx-y, cy+x, colorID)
+		p.setPixel(cx+y, cy-x, colorID)
+		p.setPixel(cx-y, cy-x, colorID)
 	}
 }
 
@@ -372,6 +380,8 @@ func (p *pixelBuffer) CircleFill(x, y, r int, colorID ...ColorID) {
 // CircleFillWithColor - fill circle with color
 func (p *pixelBuffer) circleFillWithColor(x0, y0, r int, colorID ColorID) {
 	p.fgColor = colorID
+	x0 -= p.camera.x
+	y0 -= p.camera.y
 
 	x := 0
 	y := r
@@ -381,7 +391,7 @@ func (p *pixelBuffer) circleFillWithColor(x0, y0, r int, colorID ColorID) {
 	http://groups.csail.mit.edu/graphics/classes/6.837/F98/Lecture6/circle.html
 	*/
 
-	p.circleLines(x0, y0, x, y)
+	p.circleLines(x0, y0, x, y, colorID)
 	for x < y {
 		x++
 		if p0 < 0 {
@@ -390,15 +400,15 @@ func (p *pixelBuffer) circleFillWithColor(x0, y0, r int, colorID ColorID) {
 			y--
 			p0 += 2*(x-y) + 1
 		}
-		p.circleLines(x0, y0, x, y)
+		p.circleLines(x0, y0, x, y, colorID)
 	}
 }
 
-func (p *pixelBuffer) circleLines(cx, cy, x, y int) {
-	p.Line(cx-x, cy+y, cx+x, cy+y)
-	p.Line(cx-x, cy-y, cx+x, cy-y)
-	p.Line(cx-y, cy+x, cx+y, cy+x)
-	p.Line(cx-y, cy-x, cx+y, cy-x)
+func (p *pixelBuffer) circleLines(cx, cy, x, y int, colorID ColorID) {
+	p.line(cx-x, cy+y, cx+x, cy+y, colorID)
+	p.line(cx-x, cy-y, cx+x, cy-y, colorID)
+	p.line(cx-y, cy+x, cx+y, cy+x, colorID)
+	p.line(cx-y, cy-x, cx+y, cy-x, colorID)
 }
 
 // Line - line in drawing color
@@ -414,8 +424,11 @@ func (p *pixelBuffer) Line(x0, y0, x1, y1 int, colorID ...ColorID) {
 func (p *pixelBuffer) lineWithColor(x1, y1, x2, y2 int, colorID ColorID) {
 	p.setFGColor(colorID)
 
-	col := p.palette.GetColor(colorID)
+	p.line(x1-p.camera.x, y1-p.camera.y, x2-p.camera.x, y2-p.camera.y, colorID)
+}
 
+// line - draws line in screen coords with color
+func (p *pixelBuffer) line(x1, y1, x2, y2 int, colorID ColorID) {
 	/* Code from
 	https://github.com/StephaneBunel/bresenham/blob/master/drawline.go#L12-L22
 	*/
@@ -439,15 +452,15 @@ func (p *pixelBuffer) lineWithColor(x1, y1, x2, y2 int, colorID ColorID) {
 
 	// Is line a point ?
 	case x1 == x2 && y1 == y2:
-		p.pixelSurface.Set(x1, y1, col)
+		p.setPixel(x1, y1, colorID)
 
 	// Is line an horizontal ?
 	case y1 == y2:
 		for ; dx != 0; dx-- {
-			p.pixelSurface.Set(x1, y1, col)
+			p.setPixel(x1, y1, colorID)
 			x1++
 		}
-		p.pixelSurface.Set(x1, y1, col)
+		p.setPixel(x1, y1, colorID)
 
 	// Is line a vertical ?
 	case x1 == x2:
@@ -455,27 +468,27 @@ func (p *pixelBuffer) lineWithColor(x1, y1, x2, y2 int, colorID ColorID) {
 			y1, y2 = y2, y1
 		}
 		for ; dy != 0; dy-- {
-			p.pixelSurface.Set(x1, y1, col)
+			p.setPixel(x1, y1, colorID)
 			y1++
 		}
-		p.pixelSurface.Set(x1, y1, col)
+		p.setPixel(x1, y1, colorID)
 
 	// Is line a diagonal ?
 	case dx == dy:
 		if y1 < y2 {
 			for ; dx != 0; dx-- {
-				p.pixelSurface.Set(x1, y1, col)
+				p.setPixel(x1, y1, colorID)
 				x1++
 				y1++
 			}
 		} else {
 			for ; dx != 0; dx-- {
-				p.pixelSurface.Set(x1, y1, col)
+				p.setPixel(x1, y1, colorID)
 				x1++
 				y1--
 			}
 		}
-		p.pixelSurface.Set(x1, y1, col)
+		p.setPixel(x1, y1, colorID)
 
 	// wider than high ?
 	case dx > dy:
@@ -483,7 +496,7 @@ func (p *pixelBuffer) lineWithColor(x1, y1, x2, y2 int, colorID ColorID) {
 			// BresenhamDxXRYD(img, x1, y1, x2, y2, col)
 			dy, e, slope = 2*dy, dx, 2*dx
 			for ; dx != 0; dx-- {
-				p.pixelSurface.Set(x1, y1, col)
+				p.setPixel(x1, y1, colorID)
 				x1++
 				e -= dy
 				if e < 0 {
@@ -495,7 +508,7 @@ func (p *pixelBuffer) lineWithColor(x1, y1, x2, y2 int, colorID ColorID) {
 			// BresenhamDxXRYU(img, x1, y1, x2, y2, col)
 			dy, e, slope = 2*dy, dx, 2*dx
 			for ; dx != 0; dx-- {
-				p.pixelSurface.Set(x1, y1, col)
+				p.setPixel(x1, y1, colorID)
 				x1++
 				e -= dy
 				if e < 0 {
@@ -504,7 +517,7 @@ func (p *pixelBuffer) lineWithColor(x1, y1, x2, y2 int, colorID ColorID) {
 				}
 			}
 		}
-		p.pixelSurface.Set(x2, y2, col)
+		p.setPixel(x2, y2, colorID)
 
 	// higher than wide.
 	default:
@@ -512,7 +525,7 @@ func (p *pixelBuffer) lineWithColor(x1, y1, x2, y2 int, colorID ColorID) {
 			// BresenhamDyXRYD(img, x1, y1, x2, y2, col)
 			dx, e, slope = 2*dx, dy, 2*dy
 			for ; dy != 0; dy-- {
-				p.pixelSurface.Set(x1, y1, col)
+				p.setPixel(x1, y1, colorID)
 				y1++
 				e -= dx
 				if e < 0 {
@@ -524,7 +537,7 @@ func (p *pixelBuffer) lineWithColor(x1, y1, x2, y2 int, colorID ColorID) {
 			// BresenhamDyXRYU(img, x1, y1, x2, y2, col)
 			dx, e, slope = 2*dx, dy, 2*dy
 			for ; dy != 0; dy-- {
-				p.pixelSurface.Set(x1, y1, col)
+				p.setPixel(x1, y1, colorID)
 				y1--
 				e -= dx
 				if e < 0 {
@@ -533,7 +546,7 @@ func (p *pixelBuffer) lineWithColor(x1, y1, x2, y2 int, colorID ColorID) {
 				}
 			}
 		}
-		p.pixelSurface.Set(x2, y2, col)
+		p.setPixel(x2, y2, colorID)
 	}
 }
 
@@ -549,11 +562,11 @@ func (p *pixelBuffer) setFGColor(colorID ColorID) {
 // PGet - pixel get
 func (p *pixelBuffer) PGet(x, y int) ColorID {
 
-	c := p.pixelSurface.At(x, y)
-	r, g, b, a := c.RGBA()
-	color := rgba{R: uint8(r), G: uint8(g), B: uint8(b), A: uint8(a)}
-
-	return p.palette.GetColorID(color)
+	x, y = x-p.camera.x, y-p.camera.y
+	if !(image.Point{X: x, Y: y}).In(p.psRect) {
+		return 0
+	}
+	return ColorID(p.pixelSurface.ColorIndexAt(x, y))
 }
 
 // PSet - pixel set in drawing color
@@ -568,7 +581,46 @@ func (p *pixelBuffer) PSet(x, y int, colorID ...ColorID) {
 // PSetWithColor - pixel set with color
 func (p *pixelBuffer) pSetWithColor(x0, y0 int, colorID ColorID) {
 	p.setFGColor(colorID)
-	p.pixelSurface.Set(x0, y0, p.palette.GetColor(colorID))
+	p.setPixel(x0-p.camera.x, y0-p.camera.y, colorID)
+}
+
+// setPixel - sets pixel in screen coords if inside clipping rect
+// pixels on set bits of the fill pattern use the secondary color or are skipped
+// the color is remapped by the draw palette as it is written
+func (p *pixelBuffer) setPixel(x, y int, colorID ColorID) {
+	if p.fillPattern != 0 && p.fillPattern&(0x8000>>uint((y&3)*4+(x&3))) != 0 {
+		if p.fillTransparent {
+			return
+		}
+		colorID = p.fillColor
+	}
+	p.plot(x, y, colorID)
+}
+
+// plot - sets pixel in screen coords if inside clipping rect, ignoring the fill pattern
+func (p *pixelBuffer) plot(x, y int, colorID ColorID) {
+	if !(image.Point{X: x, Y: y}).In(p.clipRect) {
+		return
+	}
+	p.pixelSurface.SetColorIndex(x, y, uint8(p.drawColor(colorID)))
+}
+
+// drawColor - returns color after remapping by the draw palette
+func (p *pixelBuffer) drawColor(colorID ColorID) ColorID {
+	colorID = p.baseColor(colorID)
+	if int(colorID) >= len(p.drawPal) {
+		return 0
+	}
+	return p.drawPal[colorID]
+}
+
+// baseColor - returns color limited to the base palette when it has secret colors
+// pico8 secret colors can only be displayed through the screen palette
+func (p *pixelBuffer) baseColor(colorID ColorID) ColorID {
+	if p.palette.secretColors {
+		return colorID & 0x0f
+	}
+	return colorID
 }
 
 // Rect - draw rectangle with drawing color
@@ -583,10 +635,12 @@ func (p *pixelBuffer) Rect(x0, y0, x1, y1 int, colorID ...ColorID) {
 // RectWithColor - draw rectangle with color
 func (p *pixelBuffer) rectWithColor(x0, y0, x1, y1 int, colorID ColorID) {
 	p.fgColor = colorID
-	p.Line(x0, y0, x1, y0)
-	p.Line(x1, y0, x1, y1)
-	p.Line(x1, y1, x0, y1)
-	p.Line(x0, y1, x0, y0)
+	x0, y0 = x0-p.camera.x, y0-p.camera.y
+	x1, y1 = x1-p.camera.x, y1-p.camera.y
+	p.line(x0, y0, x1, y0, colorID)
+	p.line(x1, y0, x1, y1, colorID)
+	p.line(x1, y1, x0, y1, colorID)
+	p.line(x0, y1, x0, y0, colorID)
 }
 
 // RectFill - fill rectangle with drawing color
@@ -601,8 +655,10 @@ func (p *pixelBuffer) RectFill(x0, y0, x1, y1 int, colorID ...ColorID) {
 // RectFillWithColor - fill rectangle with color
 func (p *pixelBuffer) rectFillWithColor(x0, y0, x1, y1 int, colorID ColorID) {
 	p.fgColor = colorID
+	x0, y0 = x0-p.camera.x, y0-p.camera.y
+	x1, y1 = x1-p.camera.x, y1-p.camera.y
 	for x := x0; x < x1; x++ {
-		p.Line(x, y0, x, y1)
+		p.line(x, y0, x, y1, colorID)
 	}
 }
 
@@ -619,16 +675,54 @@ func (p *pixelBuffer) SpriteRotated(n, x, y, w, h, dw, dh int, rot int) {
 	p.spriteWithCache(n, x, y, w, h, dw, dh, rot, false, false)
 }
 
-func (p *pixelBuffer) sprite(n, x, y, w, h, dw, dh, rot int, flipX, flipY bool) {
+// Sspr - draws sw x sh pixels at sx, sy on the sprite sheet scaled to dw x dh
+// pixels of the rect outside the sprite sheet are transparent
+func (p *pixelBuffer) Sspr(sx, sy, sw, sh, dx, dy, dw, dh int, flipX, flipY bool) {
+	src := image.Rect(sx, sy, sx+sw, sy+sh)
+	if !src.Overlaps(spriteSheet().Bounds()) {
+		return
+	}
+	p.spriteRectWithCache(src, dx, dy, dw, dh, 0, flipX, flipY)
+}
+
+// FGet - returns whether flag bit (0-7) is set for sprite n of the current bank
+func (p *pixelBuffer) FGet(n, bit int) bool {
+	if bit < 0 || bit > 7 {
+		return false
+	}
+	return spriteFlags(n)&(1<<uint(bit)) != 0
+}
 
-	_console.currentSpriteBank = userSpriteBank1
+// FSet - sets or clears flag bit (0-7) for sprite n of the current bank
+func (p *pixelBuffer) FSet(n, bit int, v bool) {
+	flags := bankFlags()
+	if n < 0 || n >= len(flags) || bit < 0 || bit > 7 {
+		return
+	}
+	if v {
+		flags[n] |= 1 << uint(bit)
+	} else {
+		flags[n] &^= 1 << uint(bit)
+	}
+}
+
+// spriteFlags - returns all flag bits for sprite n of the current bank
+func spriteFlags(n int) uint8 {
+	flags := bankFlags()
+	if n < 0 || n >= len(flags) {
+		return 0
+	}
+	return flags[n]
+}
+
+func (p *pixelBuffer) sprite(n, x, y, w, h, dw, dh, rot int, flipX, flipY bool) {
 
 	sw := w * _spriteWidth
 	sh := h * _spriteHeight
 
 	// convert sprite number into x,y pos
-	xCell := n % _spritesPerLine
-	yCell := (n - xCell) / _spritesPerLine
+	xCell := n % spritesPerLine()
+	yCell := (n - xCell) / spritesPerLine()
 
 	xPos := xCell * _spriteWidth
 	yPos := yCell * _spriteHeight
@@ -636,6 +730,7 @@ func (p *pixelBuffer) sprite(n, x, y, w, h, dw, dh, rot int, flipX, flipY bool)
 	// this is the rect to copy from sprite sheet
 	spriteSrcRect := image.Rect(xPos, yPos, xPos+sw, yPos+sh)
 	// this rect is where the sprite will be copied to
+	x, y = x-p.camera.x, y-p.camera.y
 	screenRect := image.Rect(x, y, x+dw, y+dh)
 
 	if flipX || flipY || rot != 0 {
@@ -674,54 +769,29 @@ func (p *pixelBuffer) sprite(n, x, y, w, h, dw, dh, rot int, flipX, flipY bool)
 			}
 		}
 
-		// create a copy of the sprite
-		copyRect := image.Rect(0, 0, sw, sh)
-		copyImage := image.NewPaletted(copyRect, _console.sprites[userSpriteBank1].Palette)
-		point := image.Point{X: 0, Y: 0}
-		options := &drawx.Options{
-			SrcMask:  _console.sprites[userSpriteMask1],
-			SrcMaskP: image.Point{0, 0},
-		}
-		drawx.Copy(copyImage, point, _console.sprites[userSpriteBank1], spriteSrcRect, drawx.Src, options)
-
-		// rotate it
-		txImage := image.NewPaletted(copyRect, _console.sprites[userSpriteBank1].Palette)
-		drawx.NearestNeighbor.Transform(txImage, matrix, copyImage, copyRect, drawx.Src, nil)
-
-		// create a copy of the sprite as a mask
-		maskRect := image.Rect(0, 0, sw, sh)
-		maskImage := image.NewPaletted(maskRect, _console.sprites[userSpriteMask1].Palette)
-		drawx.Copy(maskImage, point, txImage, maskRect, drawx.Src, nil)
-
-		options = &drawx.Options{
-			SrcMask:  maskImage,
-			SrcMaskP: image.Point{0, 0},
-		}
+		// transform the sprite, only covered pixels are drawn
+		txRect := image.Rect(0, 0, sw, sh)
+		txImage := image.NewPaletted(txRect, spriteSheet().Palette)
+		coverage := image.NewAlpha(txRect)
+		transformSprite(txImage, coverage, spriteSrcRect, matrix)
 
-		drawx.NearestNeighbor.Scale(p.pixelSurface, screenRect, txImage, maskRect, drawx.Over, options)
+		p.blitSprite(screenRect, txImage, txRect, coverage)
 
 		return
 	}
 
-	options := &drawx.Options{
-		SrcMask:  _console.sprites[userSpriteMask1],
-		SrcMaskP: image.Point{0, 0},
-	}
-
-	drawx.NearestNeighbor.Scale(p.pixelSurface, screenRect, _console.sprites[userSpriteBank1], spriteSrcRect, drawx.Over, options)
+	p.blitSprite(screenRect, spriteSheet(), spriteSrcRect, nil)
 
 }
 
 func (p *pixelBuffer) spriteWithMaps(n, x, y, w, h, dw, dh, rot int, flipX, flipY bool) {
 
-	_console.currentSpriteBank = userSpriteBank1
-
 	sw := w * _spriteWidth
 	sh := h * _spriteHeight
 
 	// convert sprite number into x,y pos
-	xCell := n % _spritesPerLine
-	yCell := (n - xCell) / _spritesPerLine
+	xCell := n % spritesPerLine()
+	yCell := (n - xCell) / spritesPerLine()
 
 	xPos := xCell * _spriteWidth
 	yPos := yCell * _spriteHeight
@@ -729,6 +799,7 @@ func (p *pixelBuffer) spriteWithMaps(n, x, y, w, h, dw, dh, rot int, flipX, flip
 	// this is the rect to copy from sprite sheet
 	spriteSrcRect := image.Rect(xPos, yPos, xPos+sw, yPos+sh)
 	// this rect is where the sprite will be copied to
+	x, y = x-p.camera.x, y-p.camera.y
 	screenRect := image.Rect(x, y, x+dw, y+dh)
 
 	if flipX || flipY || rot != 0 {
@@ -767,70 +838,55 @@ func (p *pixelBuffer) spriteWithMaps(n, x, y, w, h, dw, dh, rot int, flipX, flip
 			}
 		}
 
-		// create a copy of the sprite
-		copyRect := image.Rect(0, 0, sw, sh)
-		copyImage := p.getCopyImage(copyRect)
-		point := image.Point{X: 0, Y: 0}
-		options := &drawx.Options{
-			SrcMask:  _console.sprites[userSpriteMask1],
-			SrcMaskP: image.Point{0, 0},
-		}
-		drawx.Copy(copyImage, point, _console.sprites[userSpriteBank1], spriteSrcRect, drawx.Src, options)
-
-		// rotate it
-		txImage := p.getTxImage(copyRect)
+		// transform the sprite, only covered pixels are drawn
+		txRect := image.Rect(0, 0, sw, sh)
+		txImage := p.getTxImage(txRect)
+		coverage := p.getCoverageImage(txRect)
+		transformSprite(txImage, coverage, spriteSrcRect, matrix)
 
-		drawx.NearestNeighbor.Transform(txImage, matrix, copyImage, copyRect, drawx.Src, nil)
-
-		// create a copy of the sprite as a mask
-		maskRect := image.Rect(0, 0, sw, sh)
-		maskImage := p.getMaskImage(maskRect)
-		drawx.Copy(maskImage, point, txImage, maskRect, drawx.Src, nil)
-
-		options = &drawx.Options{
-			SrcMask:  maskImage,
-			SrcMaskP: image.Point{0, 0},
-		}
-
-		drawx.NearestNeighbor.Scale(p.pixelSurface, screenRect, txImage, maskRect, drawx.Over, options)
+		p.blitSprite(screenRect, txImage, txRect, coverage)
 
 		return
 	}
 
-	options := &drawx.Options{
-		SrcMask:  _console.sprites[userSpriteMask1],
-		SrcMaskP: image.Point{0, 0},
-	}
-
-	drawx.NearestNeighbor.Scale(p.pixelSurface, screenRect, _console.sprites[userSpriteBank1], spriteSrcRect, drawx.Over, options)
+	p.blitSprite(screenRect, spriteSheet(), spriteSrcRect, nil)
 
 }
 
 func (p *pixelBuffer) spriteWithCache(n, x, y, w, h, dw, dh, rot int, flipX, flipY bool) {
 
-	_console.currentSpriteBank = userSpriteBank1
-
 	sw := w * _spriteWidth
 	sh := h * _spriteHeight
 
 	// convert sprite number into x,y pos
-	xCell := n % _spritesPerLine
-	yCell := (n - xCell) / _spritesPerLine
+	xCell := n % spritesPerLine()
+	yCell := (n - xCell) / spritesPerLine()
 
 	xPos := xCell * _spriteWidth
 	yPos := yCell * _spriteHeight
 
 	// this is the rect to copy from sprite sheet
 	spriteSrcRect := image.Rect(xPos, yPos, xPos+sw, yPos+sh)
+
+	p.spriteRectWithCache(spriteSrcRect, x, y, dw, dh, rot, flipX, flipY)
+}
+
+// spriteRectWithCache - draws pixels from rect on the sprite sheet scaled to dw, dh
+// transformed sprites are cached by rect, scale & transform
+func (p *pixelBuffer) spriteRectWithCache(spriteSrcRect image.Rectangle, x, y, dw, dh, rot int, flipX, flipY bool) {
+
+	sw := spriteSrcRect.Dx()
+	sh := spriteSrcRect.Dy()
+
 	// this rect is where the sprite will be copied to
+	x, y = x-p.camera.x, y-p.camera.y
 	screenRect := image.Rect(x, y, x+dw, y+dh)
 
 	if flipX || flipY || rot != 0 {
 
 		tx := spriteTx{
-			number:      n,
-			width:       w,
-			height:      h,
+			bank:        _console.currentSpriteBank,
+			src:         spriteSrcRect,
 			scaleWidth:  dw,
 			scaleHeight: dh,
 			rot:         rot,
@@ -877,42 +933,21 @@ func (p *pixelBuffer) spriteWithCache(n, x, y, w, h, dw, dh, rot int, flipX, fli
 				}
 			}
 
-			// create a copy of the sprite
-			copyRect := image.Rect(0, 0, sw, sh)
-			// this fetches and empty image of the correct dimensions
-			copyImage := image.NewPaletted(copyRect, _console.sprites[userSpriteBank1].Palette)
-
-			point := image.Point{X: 0, Y: 0}
-			options := &drawx.Options{
-				SrcMask:  _console.sprites[userSpriteMask1],
-				SrcMaskP: image.Point{0, 0},
-			}
-			drawx.Copy(copyImage, point, _console.sprites[userSpriteBank1], spriteSrcRect, drawx.Src, options)
-
-			// rotate it
-			txImage := image.NewPaletted(copyRect, _console.sprites[userSpriteBank1].Palette)
-
-			drawx.NearestNeighbor.Transform(txImage, matrix, copyImage, copyRect, drawx.Src, nil)
+			// transform the sprite, color ids are cached so the draw palette
+			// & transparent colors are applied each time it is drawn
+			txRect := image.Rect(0, 0, sw, sh)
+			txImage := image.NewPaletted(txRect, spriteSheet().Palette)
+			coverage := image.NewAlpha(txRect)
+			transformSprite(txImage, coverage, spriteSrcRect, matrix)
 
-			// create a copy of the sprite as a mask
-			maskRect := image.Rect(0, 0, sw, sh)
-			maskImage := image.NewPaletted(maskRect, _console.sprites[userSpriteMask1].Palette)
-
-			drawx.Copy(maskImage, point, txImage, maskRect, drawx.Src, nil)
-
-			options = &drawx.Options{
-				SrcMask:  maskImage,
-				SrcMaskP: image.Point{0, 0},
-			}
-
-			drawx.NearestNeighbor.Scale(p.pixelSurface, screenRect, txImage, maskRect, drawx.Over, options)
+			p.blitSprite(screenRect, txImage, txRect, coverage)
 
 			// store in cache
 
 			p.spriteCache[tx] = spriteCached{
-				txImage:   txImage,
-				maskImage: maskImage,
-				lastUsed:  time.Now(),
+				txImage:  txImage,
+				coverage: coverage,
+				lastUsed: time.Now(),
 			}
 
 			if len(p.spriteCache) > MaxSpriteCache {
@@ -938,13 +973,7 @@ func (p *pixelBuffer) spriteWithCache(n, x, y, w, h, dw, dh, rot int, flipX, fli
 			//			fmt.Printf("TEMP: tx: %#v\n", tx)
 			//fmt.Printf("TEMP: cached: %#v\n", cached)
 
-			options := &drawx.Options{
-				SrcMask:  cached.maskImage,
-				SrcMaskP: image.Point{0, 0},
-			}
-			maskRect := image.Rect(0, 0, sw, sh)
-
-			drawx.NearestNeighbor.Scale(p.pixelSurface, screenRect, cached.txImage, maskRect, drawx.Over, options)
+			p.blitSprite(screenRect, cached.txImage, cached.txImage.Bounds(), cached.coverage)
 
 			// update last used time
 			cached.lastUsed = time.Now()
@@ -956,26 +985,7 @@ func (p *pixelBuffer) spriteWithCache(n, x, y, w, h, dw, dh, rot int, flipX, fli
 
 	}
 
-	options := &drawx.Options{
-		SrcMask:  _console.sprites[userSpriteMask1],
-		SrcMaskP: image.Point{0, 0},
-	}
-
-	drawx.NearestNeighbor.Scale(p.pixelSurface, screenRect, _console.sprites[userSpriteBank1], spriteSrcRect, drawx.Over, options)
-}
-
-// getCopyImage returns an empty image with the correct dimensions
-func (p *pixelBuffer) getCopyImage(r image.Rectangle) *image.Paletted {
-
-	copyImage, ok := p.copySpritesMap[r]
-	if ok {
-		return copyImage
-	}
-
-	copyImage = image.NewPaletted(r, _console.sprites[userSpriteBank1].Palette)
-
-	p.copySpritesMap[r] = copyImage
-	return copyImage
+	p.blitSprite(screenRect, spriteSheet(), spriteSrcRect, nil)
 }
 
 func (p *pixelBuffer) getTxImage(r image.Rectangle) *image.Paletted {
@@ -989,23 +999,173 @@ func (p *pixelBuffer) getTxImage(r image.Rectangle) *image.Paletted {
 		return txImage
 	}
 
-	txImage = image.NewPaletted(r, _console.sprites[userSpriteBank1].Palette)
+	txImage = image.NewPaletted(r, spriteSheet().Palette)
 
 	p.txSpritesMap[r] = txImage
 	return txImage
 }
 
-func (p *pixelBuffer) getMaskImage(r image.Rectangle) *image.Paletted {
+func (p *pixelBuffer) getCoverageImage(r image.Rectangle) *image.Alpha {
 
-	maskImage, ok := p.maskSpritesMap[r]
+	coverage, ok := p.coverageSpritesMap[r]
 	if ok {
-		return maskImage
+		return coverage
+	}
+
+	coverage = image.NewAlpha(r)
+
+	p.coverageSpritesMap[r] = coverage
+	return coverage
+}
+
+// transformSprite - copies color ids from rect on the sprite sheet through the transform into tx
+// using nearest neighbour sampling, coverage is opaque where the transformed sprite lands on the sheet
+func transformSprite(tx *image.Paletted, coverage *image.Alpha, src image.Rectangle, s2d f64.Aff3) {
+	sheet := spriteSheet()
+	bounds := sheet.Bounds()
+
+	// invert transform to find the source pixel of each destination pixel
+	det := s2d[0]*s2d[4] - s2d[1]*s2d[3]
+	d2s := f64.Aff3{
+		s2d[4] / det, -s2d[1] / det, (s2d[1]*s2d[5] - s2d[4]*s2d[2]) / det,
+		-s2d[3] / det, s2d[0] / det, (s2d[3]*s2d[2] - s2d[0]*s2d[5]) / det,
+	}
+
+	for y := 0; y < tx.Rect.Dy(); y++ {
+		dy := float64(y) + 0.5
+		for x := 0; x < tx.Rect.Dx(); x++ {
+			dx := float64(x) + 0.5
+			sx := int(math.Floor(d2s[0]*dx + d2s[1]*dy + d2s[2]))
+			sy := int(math.Floor(d2s[3]*dx + d2s[4]*dy + d2s[5]))
+			i := y*tx.Stride + x
+			sp := image.Point{X: src.Min.X + sx, Y: src.Min.Y + sy}
+			if sx < 0 || sy < 0 || sx >= src.Dx() || sy >= src.Dy() || !sp.In(bounds) {
+				tx.Pix[i] = 0
+				coverage.Pix[i] = 0
+				continue
+			}
+			tx.Pix[i] = sheet.ColorIndexAt(sp.X, sp.Y)
+			coverage.Pix[i] = 0xff
+		}
+	}
+}
+
+// blitSprite - scales color ids from rect src of img into rect dst of the screen using nearest neighbour sampling
+// ids are remapped by the draw palette, transparent colors & pixels outside coverage (when set) are skipped
+// pixels outside img are skipped and drawing is limited to the clipping rect
+func (p *pixelBuffer) blitSprite(dst image.Rectangle, img *image.Paletted, src image.Rectangle, coverage *image.Alpha) {
+	if dst.Empty() || src.Empty() {
+		return
 	}
+	dw, dh := dst.Dx(), dst.Dy()
+	sw, sh := src.Dx(), src.Dy()
+	bounds := img.Bounds()
+	clipped := dst.Intersect(p.clipRect)
+	for y := clipped.Min.Y; y < clipped.Max.Y; y++ {
+		sy := src.Min.Y + (2*(y-dst.Min.Y)+1)*sh/(2*dh)
+		if sy < bounds.Min.Y || sy >= bounds.Max.Y {
+			continue
+		}
+		row := p.pixelSurface.Pix[p.pixelSurface.PixOffset(0, y):]
+		for x := clipped.Min.X; x < clipped.Max.X; x++ {
+			sx := src.Min.X + (2*(x-dst.Min.X)+1)*sw/(2*dw)
+			if sx < bounds.Min.X || sx >= bounds.Max.X {
+				continue
+			}
+			if coverage != nil && coverage.Pix[coverage.PixOffset(sx, sy)] == 0 {
+				continue
+			}
+			c := img.Pix[img.PixOffset(sx, sy)]
+			if int(c) >= len(p.drawPal) || p.transparent[c] {
+				continue
+			}
+			row[x-p.pixelSurface.Rect.Min.X] = uint8(p.drawPal[c])
+		}
+	}
+}
 
-	maskImage = image.NewPaletted(r, _console.sprites[userSpriteMask1].Palette)
+// Camera - sets offset subtracted from all drawing coords
+func (p *pixelBuffer) Camera(x, y int) {
+	p.camera.x = x
+	p.camera.y = y
+}
 
-	p.maskSpritesMap[r] = maskImage
-	return maskImage
+// Clip - limits all drawing to rect at x, y of size w, h
+func (p *pixelBuffer) Clip(x, y, w, h int) {
+	p.setClipRect(image.Rect(x, y, x+w, y+h))
+}
+
+// setClipRect - sets clipping rect kept on screen & its bytes in memory
+func (p *pixelBuffer) setClipRect(r image.Rectangle) {
+	p.clipRect = r.Intersect(p.psRect)
+	p.clipBytes = [4]uint8{
+		uint8(p.clipRect.Min.X), uint8(p.clipRect.Min.Y),
+		uint8(p.clipRect.Max.X), uint8(p.clipRect.Max.Y),
+	}
+}
+
+// Fillp - sets 4x4 fill pattern for shapes, bit 15 is the top left pixel
+// pixels on set bits are drawn in the secondary color (default 0) or skipped when transparent
+func (p *pixelBuffer) Fillp(pattern uint16, transparent bool, secondary ...ColorID) {
+	p.fillPattern = pattern
+	p.fillTransparent = transparent
+	p.fillColor = 0
+	if len(secondary) > 0 {
+		p.fillColor = secondary[0]
+	}
+}
+
+// Pal - remaps color c0 to c1 in the draw palette, or the screen palette with mode PAL_SCREEN
+// the draw palette is applied as shapes, sprites & text are drawn
+// the screen palette is applied to the whole frame when it is displayed
+// screen colors from 128 select the colors after the first 16, eg. pico8's secret colors
+func (p *pixelBuffer) Pal(c0, c1 ColorID, mode ...int) {
+	screen := len(mode) > 0 && mode[0] == PAL_SCREEN
+	if screen && int(c1) >= len(p.screenPal) && c1 >= _secretColors {
+		c1 = c1 - _secretColors + TOTAL_COLORS
+	}
+	if int(c0) >= len(p.drawPal) || int(c1) >= len(p.drawPal) {
+		return
+	}
+	if screen {
+		p.screenPal[c0] = c1
+		p.palette.colors[c0] = p.palette.originalColors[c1]
+		p.palette.updateColorMaps()
+		return
+	}
+	p.drawPal[c0] = p.baseColor(c1)
+}
+
+// PalReset - resets the draw & screen palettes and sprite transparency
+func (p *pixelBuffer) PalReset() {
+	total := len(p.palette.originalColors)
+	p.drawPal = make([]ColorID, total)
+	p.screenPal = make([]ColorID, total)
+	for i := 0; i < total; i++ {
+		p.drawPal[i] = ColorID(i)
+		p.screenPal[i] = ColorID(i)
+	}
+	p.palette.PaletteReset()
+	p.PaltReset()
+}
+
+// Palt - sets whether color is transparent when drawing sprites
+func (p *pixelBuffer) Palt(colorID ColorID, transparent bool) {
+	if int(colorID) >= len(p.transparent) {
+		return
+	}
+	p.transparent[colorID] = transparent
+}
+
+// PaltReset - resets sprite transparency so only color 0 is transparent
+func (p *pixelBuffer) PaltReset() {
+	p.transparent = make([]bool, len(p.palette.originalColors))
+	p.transparent[0] = true
+}
+
+// clipSurface - returns the part of the pixel surface inside the clipping rect
+func (p *pixelBuffer) clipSurface() *image.Paletted {
+	return p.pixelSurface.SubImage(p.clipRect).(*image.Paletted)
 }
 
 // SetColor - Set current drawing color
@@ -1037,39 +1197,73 @@ func (p *pixelBuffer) GetColors() []color.Color {
 
 func (p *pixelBuffer) PaletteReset() {
 	p.palette.PaletteReset()
+	for i := range p.screenPal {
+		p.screenPal[i] = ColorID(i)
+	}
 }
 
 func (p *pixelBuffer) PaletteCopy() Paletter {
 	return p.palette.PaletteCopy()
 }
 
-func (p *pixelBuffer) Peek(pos int) uint8 {
-	if pos > 0 && pos < len(p.pixelSurface.Pix) {
-		return p.pixelSurface.Pix[pos]
-	}
-	return 0
-}
-
-func (p *pixelBuffer) Poke(pos int, value uint8) {
-	if pos > 0 && pos < len(p.pixelSurface.Pix) {
-		p.pixelSurface.Pix[pos] = value
-	}
-}
-
+// MapColor - remap color in the screen palette
 func (p *pixelBuffer) MapColor(fromColor ColorID, toColor ColorID) error {
 	if err := p.palette.MapColor(fromColor, toColor); err != nil {
 		return err
 	}
-	// update palette for surface
-	return setSurfacePalette(p.palette, p.pixelSurface)
+	p.screenPal[fromColor] = toColor
+	return nil
 }
 
+// SetTransparent - display color as color 0 in the screen palette
 func (p *pixelBuffer) SetTransparent(color ColorID, enabled bool) error {
 	if err := p.palette.SetTransparent(color, enabled); err != nil {
 		return err
 	}
-	// update palette for surface
-	return setSurfacePalette(p.palette, p.pixelSurface)
+	if enabled {
+		p.screenPal[color] = 0
+	} else {
+		p.screenPal[color] = color
+	}
+	return nil
+}
+
+// SetPalette - replaces the palette with 0xRRGGBB colors and resets the draw & screen palettes
+// pixels outside the new palette are set to color 0
+func (p *pixelBuffer) SetPalette(rgb ...uint32) error {
+	if err := p.palette.SetPalette(rgb...); err != nil {
+		return err
+	}
+	total := len(rgb)
+	for i, c := range p.pixelSurface.Pix {
+		if int(c) >= total {
+			p.pixelSurface.Pix[i] = 0
+		}
+	}
+	p.pixelSurface.Palette = append(color.Palette{}, p.palette.originalColors...)
+
+	// sprite colors outside the palette are not drawn, the base colors of
+	// the built in sheet are kept
+	size := total
+	if size < TOTAL_COLORS {
+		size = TOTAL_COLORS
+	}
+	for _, sheet := range _console.sprites {
+		for j, c := range sheet.Pix {
+			if int(c) >= size {
+				sheet.Pix[j] = 0
+			}
+		}
+		sheet.Palette = p.palette.originalColors
+	}
+
+	// cached images use the old palettes
+	p.spriteCache = make(map[spriteTx]spriteCached)
+	p.txSpritesMap = make(map[image.Rectangle]*image.Paletted)
+	p.coverageSpritesMap = make(map[image.Rectangle]*image.Alpha)
+
+	p.PalReset()
+	return nil
 }
 
 // Destroy cleans up any resources at end
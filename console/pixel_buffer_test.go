@@ -1,6 +1,9 @@
 package console
 
 import (
+	"bytes"
+	"image"
+	"image/png"
 	"testing"
 )
 
@@ -76,12 +79,402 @@ func TestRotatedWithCache(t *testing.T) {
 		t.Errorf("Tx image addresses should not match: %#v vs %#v", sprites[0].txImage, sprites[1].txImage)
 	}
 
-	if &sprites[0].maskImage == &sprites[1].maskImage {
-		t.Errorf("Mask image addresses should not match: %#v vs %#v", sprites[0].maskImage, sprites[1].maskImage)
+	if &sprites[0].coverage == &sprites[1].coverage {
+		t.Errorf("Coverage image addresses should not match: %#v vs %#v", sprites[0].coverage, sprites[1].coverage)
 	}
 
 }
 
+func TestSpriteColorIDs(t *testing.T) {
+	Init(ZXSPECTRUM)
+	pb := _console.pb
+
+	// bright black has the same rgb as black but is a different color
+	pb.Poke(MEM_SPRITES, 0x98)
+	pb.Cls(ZX_WHITE)
+	pb.Sprite(0, 0, 0, 1, 1, 8, 8)
+	pb.SpriteFlipped(0, 8, 0, 1, 1, 8, 8, true, false)
+	if pb.PGet(0, 0) != ZX_BRIGHT_BLACK || pb.PGet(15, 0) != ZX_BRIGHT_BLACK {
+		t.Errorf("Expected bright black got: %d, %d", pb.PGet(0, 0), pb.PGet(15, 0))
+	}
+	if pb.PGet(1, 0) != ZX_BRIGHT_BLUE || pb.PGet(14, 0) != ZX_BRIGHT_BLUE {
+		t.Errorf("Expected bright blue got: %d, %d", pb.PGet(1, 0), pb.PGet(14, 0))
+	}
+
+	// the draw palette & transparency target the color, not its rgb
+	pb.Pal(ZX_BRIGHT_BLACK, ZX_RED)
+	pb.Palt(ZX_BRIGHT_BLUE, true)
+	pb.Cls(ZX_WHITE)
+	pb.Sprite(0, 0, 0, 1, 1, 8, 8)
+	pb.SpriteFlipped(0, 8, 0, 1, 1, 8, 8, true, false)
+	if pb.PGet(0, 0) != ZX_RED || pb.PGet(15, 0) != ZX_RED {
+		t.Errorf("Expected bright black drawn as red got: %d, %d", pb.PGet(0, 0), pb.PGet(15, 0))
+	}
+	if pb.PGet(1, 0) != ZX_WHITE || pb.PGet(14, 0) != ZX_WHITE {
+		t.Errorf("Expected bright blue to be transparent got: %d, %d", pb.PGet(1, 0), pb.PGet(14, 0))
+	}
+	pb.PalReset()
+}
+
+func TestSspr(t *testing.T) {
+	Init(PICO8)
+	pb := _console.pb
+
+	// 3x2 rect of colors that are not 8 pixel aligned
+	sheet := _console.sprites[userSpriteBank1]
+	colors := [][]uint8{{8, 9, 10}, {11, 0, 12}}
+	for y, row := range colors {
+		for x, c := range row {
+			sheet.SetColorIndex(13+x, 5+y, c)
+		}
+	}
+
+	pb.Cls(PICO8_WHITE)
+	pb.Sspr(13, 5, 3, 2, 0, 0, 3, 2, false, false)
+	pb.Sspr(13, 5, 3, 2, 0, 4, 6, 4, true, false)
+	for y, row := range colors {
+		for x, c := range row {
+			want := ColorID(c)
+			if c == 0 {
+				want = PICO8_WHITE
+			}
+			if got := pb.PGet(x, y); got != want {
+				t.Errorf("Expected pixel %d,%d to be: %d got: %d", x, y, want, got)
+			}
+			// flipped & scaled x2
+			if got := pb.PGet(5-x*2, 4+y*2); got != want {
+				t.Errorf("Expected scaled pixel %d,%d to be: %d got: %d", 5-x*2, 4+y*2, want, got)
+			}
+		}
+	}
+
+	// flipped rects are cached by sheet rect
+	if _, ok := pb.spriteCache[spriteTx{src: image.Rect(13, 5, 16, 7), scaleWidth: 6, scaleHeight: 4, flipX: true}]; !ok {
+		t.Errorf("Expected flipped rect to be cached")
+	}
+
+	// pixels of the rect off the sheet are transparent, not stretched
+	for x := 120; x < 128; x++ {
+		sheet.SetColorIndex(x, 0, uint8(PICO8_RED))
+	}
+	pb.Cls(PICO8_WHITE)
+	pb.Sspr(120, 0, 16, 8, 0, 0, 16, 8, false, false)
+	pb.Sspr(120, 0, 16, 8, 0, 8, 16, 8, true, false)
+	if pb.PGet(7, 0) != PICO8_RED || pb.PGet(8, 0) != PICO8_WHITE || pb.PGet(15, 0) != PICO8_WHITE {
+		t.Errorf("Expected sheet pixels then transparent got: %d, %d, %d", pb.PGet(7, 0), pb.PGet(8, 0), pb.PGet(15, 0))
+	}
+	if pb.PGet(0, 8) != PICO8_WHITE || pb.PGet(7, 8) != PICO8_WHITE || pb.PGet(8, 8) != PICO8_RED {
+		t.Errorf("Expected flipped transparent then sheet pixels got: %d, %d, %d", pb.PGet(0, 8), pb.PGet(7, 8), pb.PGet(8, 8))
+	}
+}
+
+func TestCameraOffset(t *testing.T) {
+	Init(PICO8)
+	pb := _console.pb
+
+	pb.Cls()
+	pb.Camera(10, 20)
+	pb.PSet(15, 25, PICO8_RED)
+
+	if got := pb.pixelSurface.ColorIndexAt(5, 5); got != uint8(PICO8_RED) {
+		t.Errorf("Expected color: %d at camera offset got: %d", PICO8_RED, got)
+	}
+	if got := pb.PGet(15, 25); got != PICO8_RED {
+		t.Errorf("Expected PGet color: %d got: %d", PICO8_RED, got)
+	}
+	pb.Camera(0, 0)
+}
+
+func TestClip(t *testing.T) {
+	Init(PICO8)
+	pb := _console.pb
+
+	// draw an unclipped sprite to compare against
+	pb.Cls()
+	pb.Sprite(1, 0, 0, 2, 2, 16, 16)
+	expected := make([]uint8, len(pb.pixelSurface.Pix))
+	copy(expected, pb.pixelSurface.Pix)
+
+	pb.Cls()
+	pb.Clip(4, 4, 8, 8)
+	pb.Sprite(1, 0, 0, 2, 2, 16, 16)
+	pb.RectFill(0, 0, 128, 128, PICO8_RED)
+
+	clip := image.Rect(4, 4, 12, 12)
+	for y := 0; y < pb.GetHeight(); y++ {
+		for x := 0; x < pb.GetWidth(); x++ {
+			got := pb.pixelSurface.ColorIndexAt(x, y)
+			inside := image.Point{X: x, Y: y}.In(clip)
+			if !inside && got != 0 {
+				t.Fatalf("Expected no drawing outside clip at %d,%d got: %d", x, y, got)
+			}
+		}
+	}
+
+	// redraw sprite on top of fill inside clip only
+	pb.Cls()
+	pb.Clip(4, 4, 8, 8)
+	pb.Sprite(1, 0, 0, 2, 2, 16, 16)
+	for y := clip.Min.Y; y < clip.Max.Y; y++ {
+		for x := clip.Min.X; x < clip.Max.X; x++ {
+			want := expected[pb.pixelSurface.PixOffset(x, y)]
+			if got := pb.pixelSurface.ColorIndexAt(x, y); got != want {
+				t.Fatalf("Expected clipped sprite pixel %d,%d to be: %d got: %d", x, y, want, got)
+			}
+		}
+	}
+}
+
+func TestFillp(t *testing.T) {
+	Init(PICO8)
+	pb := _console.pb
+
+	// checkerboard, top left pixel is a set bit
+	pb.Cls()
+	pb.Fillp(0xa5a5, false, PICO8_BLUE)
+	pb.RectFill(0, 0, 8, 8, PICO8_RED)
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 8; x++ {
+			want := uint8(PICO8_BLUE)
+			if (x+y)%2 == 1 {
+				want = uint8(PICO8_RED)
+			}
+			if got := pb.pixelSurface.ColorIndexAt(x, y); got != want {
+				t.Fatalf("Expected fill pattern pixel %d,%d to be: %d got: %d", x, y, want, got)
+			}
+		}
+	}
+
+	// transparent set bits leave the background
+	pb.Cls(PICO8_GREEN)
+	pb.Fillp(0xa5a5, true)
+	pb.Line(0, 0, 7, 0, PICO8_RED)
+	for x := 0; x < 8; x++ {
+		want := uint8(PICO8_GREEN)
+		if x%2 == 1 {
+			want = uint8(PICO8_RED)
+		}
+		if got := pb.pixelSurface.ColorIndexAt(x, 0); got != want {
+			t.Fatalf("Expected transparent pattern pixel %d,0 to be: %d got: %d", x, want, got)
+		}
+	}
+
+	// pattern is also available through draw state memory
+	if pb.Peek2(_memFillp) != 0xa5a5-0x10000 || pb.Peek(_memFillp+2) != 1 {
+		t.Errorf("Expected fill pattern in memory got %#x, %d", pb.Peek2(_memFillp), pb.Peek(_memFillp+2))
+	}
+
+	pb.Fillp(0, false)
+}
+
+func TestPal(t *testing.T) {
+	Init(PICO8)
+	pb := _console.pb
+
+	// draw palette remaps shapes & sprites as they are drawn
+	pb.Cls()
+	pb.Poke(MEM_SPRITES, 0x88)
+	pb.Pal(PICO8_RED, PICO8_BLUE)
+	pb.RectFill(0, 0, 4, 4, PICO8_RED)
+	pb.Sprite(0, 8, 0, 1, 1, 8, 8)
+	if pb.PGet(0, 0) != PICO8_BLUE || pb.PGet(8, 0) != PICO8_BLUE {
+		t.Errorf("Expected red to be drawn as blue got: %d, %d", pb.PGet(0, 0), pb.PGet(8, 0))
+	}
+	if pb.GetColor(PICO8_RED) != pb.palette.originalColors[PICO8_RED] {
+		t.Errorf("Expected draw palette not to change the screen palette")
+	}
+
+	// screen palette remaps the displayed color, not the pixels
+	pb.PalReset()
+	pb.PSet(0, 0, PICO8_RED)
+	pb.Pal(PICO8_RED, PICO8_GREEN, PAL_SCREEN)
+	if pb.PGet(0, 0) != PICO8_RED {
+		t.Errorf("Expected pixel to stay red got: %d", pb.PGet(0, 0))
+	}
+	if pb.GetColor(PICO8_RED) != pb.palette.originalColors[PICO8_GREEN] {
+		t.Errorf("Expected red to be displayed as green got: %v", pb.GetColor(PICO8_RED))
+	}
+	if pb.Peek(_memScreenPal+int(PICO8_RED)) != uint8(PICO8_GREEN) || pb.Peek(_memDrawPal+int(PICO8_RED)) != uint8(PICO8_RED) {
+		t.Errorf("Expected palettes in memory got draw: %d screen: %d", pb.Peek(_memDrawPal+int(PICO8_RED)), pb.Peek(_memScreenPal+int(PICO8_RED)))
+	}
+
+	// draw palette can be poked
+	pb.Poke(_memDrawPal+int(PICO8_RED), uint8(PICO8_PINK))
+	pb.PSet(1, 0, PICO8_RED)
+	if pb.PGet(1, 0) != PICO8_PINK {
+		t.Errorf("Expected poked draw palette to draw red as pink got: %d", pb.PGet(1, 0))
+	}
+
+	pb.PalReset()
+	if pb.GetColor(PICO8_RED) != pb.palette.originalColors[PICO8_RED] || pb.drawColor(PICO8_RED) != PICO8_RED {
+		t.Errorf("Expected palettes to be reset")
+	}
+}
+
+func TestPalt(t *testing.T) {
+	Init(PICO8)
+	pb := _console.pb
+
+	// left pixel black, right pixel white
+	pb.Poke(MEM_SPRITES, 0x70)
+
+	// by default only black is transparent
+	pb.Cls(PICO8_RED)
+	pb.Sprite(0, 0, 0, 1, 1, 8, 8)
+	if pb.PGet(0, 0) != PICO8_RED || pb.PGet(1, 0) != PICO8_WHITE {
+		t.Errorf("Expected black to be transparent got: %d, %d", pb.PGet(0, 0), pb.PGet(1, 0))
+	}
+
+	pb.Palt(PICO8_BLACK, false)
+	pb.Palt(PICO8_WHITE, true)
+	pb.Cls(PICO8_RED)
+	pb.Sprite(0, 0, 0, 1, 1, 8, 8)
+	if pb.PGet(0, 0) != PICO8_BLACK || pb.PGet(1, 0) != PICO8_RED {
+		t.Errorf("Expected white to be transparent got: %d, %d", pb.PGet(0, 0), pb.PGet(1, 0))
+	}
+	if pb.Peek(_memDrawPal+int(PICO8_WHITE)) != 0x10|uint8(PICO8_WHITE) {
+		t.Errorf("Expected transparency bit in memory got: %#x", pb.Peek(_memDrawPal+int(PICO8_WHITE)))
+	}
+
+	pb.PaltReset()
+	if !pb.transparent[PICO8_BLACK] || pb.transparent[PICO8_WHITE] {
+		t.Errorf("Expected only black to be transparent after reset")
+	}
+}
+
+func TestPaltTransformed(t *testing.T) {
+	Init(PICO8)
+	pb := _console.pb
+
+	// sprite 0 is black with a dark blue top left pixel
+	for y := 0; y < 8; y++ {
+		pb.Memset(MEM_SPRITES+y*64, 0, 4)
+	}
+	pb.Poke(MEM_SPRITES, 0x01)
+
+	// flipped sprite keeps dark colors & hides black
+	pb.Cls(PICO8_RED)
+	pb.SpriteFlipped(0, 0, 0, 1, 1, 8, 8, true, false)
+	if pb.PGet(7, 0) != PICO8_DARK_BLUE || pb.PGet(6, 0) != PICO8_RED {
+		t.Errorf("Expected dark blue then transparent got: %d, %d", pb.PGet(7, 0), pb.PGet(6, 0))
+	}
+
+	// cached sprite follows changes to transparency
+	pb.Palt(PICO8_BLACK, false)
+	pb.Palt(PICO8_DARK_BLUE, true)
+	pb.Cls(PICO8_RED)
+	pb.SpriteFlipped(0, 0, 0, 1, 1, 8, 8, true, false)
+	if len(pb.spriteCache) != 1 {
+		t.Errorf("Expected flipped sprite to be cached once got: %d", len(pb.spriteCache))
+	}
+	if pb.PGet(7, 0) != PICO8_RED || pb.PGet(6, 0) != PICO8_BLACK {
+		t.Errorf("Expected transparent then black got: %d, %d", pb.PGet(7, 0), pb.PGet(6, 0))
+	}
+
+	// pixels outside a rotated sprite are never drawn
+	pb.Cls(PICO8_RED)
+	pb.SpriteRotated(0, 0, 0, 1, 1, 8, 8, 45)
+	if pb.PGet(0, 0) != PICO8_RED || pb.PGet(4, 4) != PICO8_BLACK {
+		t.Errorf("Expected only rotated sprite to be drawn got: %d, %d", pb.PGet(0, 0), pb.PGet(4, 4))
+	}
+	pb.PaltReset()
+}
+
+func TestSecretColors(t *testing.T) {
+	Init(PICO8)
+	pb := _console.pb
+
+	if len(pb.GetColors()) != 32 {
+		t.Fatalf("Expected 32 pico8 colors got: %d", len(pb.GetColors()))
+	}
+
+	// secret colors are addressed from 128 in the screen palette
+	pb.Pal(PICO8_RED, 128+3, PAL_SCREEN)
+	if pb.GetColor(PICO8_RED) != pb.palette.originalColors[PICO8_BLUE_GREEN] {
+		t.Errorf("Expected red to be displayed as blue green got: %v", pb.GetColor(PICO8_RED))
+	}
+	if pb.Peek(_memScreenPal+int(PICO8_RED)) != 0x83 {
+		t.Errorf("Expected secret color in memory got: %#x", pb.Peek(_memScreenPal+int(PICO8_RED)))
+	}
+	pb.Poke(_memScreenPal+int(PICO8_BLUE), 0x8c)
+	if pb.screenPal[PICO8_BLUE] != PICO8_TRUE_BLUE {
+		t.Errorf("Expected poked secret color got: %d", pb.screenPal[PICO8_BLUE])
+	}
+	pb.PalReset()
+
+	// secret colors are not drawable, draw colors are limited to the base 16
+	pb.Cls()
+	pb.PSet(0, 0, PICO8_DARK_BROWN)
+	pb.SetColor(PICO8_LIGHT_PEACH)
+	pb.PSet(1, 0)
+	pb.Pal(PICO8_RED, PICO8_DARK_RED)
+	pb.PSet(2, 0, PICO8_RED)
+	pb.PalReset()
+	expected := []ColorID{PICO8_BROWN, PICO8_PEACH, PICO8_RED}
+	for x, want := range expected {
+		if got := pb.PGet(x, 0); got != want {
+			t.Errorf("Expected pixel %d to be: %d got: %d", x, want, got)
+		}
+	}
+
+	// sprites are quantized to the base 16 colors
+	img := image.NewRGBA(image.Rect(0, 0, 8, 8))
+	img.Set(0, 0, pb.palette.originalColors[PICO8_DARK_ORANGE])
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("Failed to encode png: %s", err)
+	}
+	bank, err := pb.LoadSprites(buf.Bytes())
+	if err != nil {
+		t.Fatalf("Failed to load sprites: %s", err)
+	}
+	if got := _console.sprites[bank].Pix[0]; got != uint8(PICO8_ORANGE) {
+		t.Errorf("Expected secret color to be quantized to orange got: %d", got)
+	}
+}
+
+func TestSetPalette(t *testing.T) {
+	Init(PICO8)
+	pb := _console.pb
+
+	if err := pb.SetPalette(); err == nil {
+		t.Errorf("Expected error setting empty palette")
+	}
+	if err := pb.SetPalette(make([]uint32, MAX_COLORS+1)...); err == nil {
+		t.Errorf("Expected error setting palette larger than %d colors", MAX_COLORS)
+	}
+
+	// pixels outside the new palette become color 0
+	pb.PSet(0, 0, PICO8_WHITE)
+	if err := pb.SetPalette(0x000000, 0xff0000, 0x00ff00, 0x0000ff); err != nil {
+		t.Fatalf("Failed to set palette: %s", err)
+	}
+	if pb.PGet(0, 0) != 0 {
+		t.Errorf("Expected pixel outside palette to be cleared got: %d", pb.PGet(0, 0))
+	}
+	pb.PSet(1, 0, 3)
+	pb.Pal(1, 2)
+	pb.PSet(2, 0, 1)
+	pb.copyIndexedToRGBA()
+	if got := pb.rgbaPixels[4:12]; got[2] != 255 || got[4] != 0 || got[5] != 255 {
+		t.Errorf("Expected blue then green pixels got: %v", got)
+	}
+
+	// every index of a full palette is displayed
+	rgb := make([]uint32, MAX_COLORS)
+	for i := range rgb {
+		rgb[i] = uint32(i)
+	}
+	if err := pb.SetPalette(rgb...); err != nil {
+		t.Fatalf("Failed to set palette: %s", err)
+	}
+	pb.PSet(0, 0, 255)
+	pb.copyIndexedToRGBA()
+	if pb.rgbaPixels[2] != 255 {
+		t.Errorf("Expected color 255 to be displayed got: %v", pb.rgbaPixels[:4])
+	}
+}
+
 func BenchmarkCopyPixels(b *testing.B) {
 	// this benchmark measures the performance of the code the copies the offset pixelbuffer into an array of RGBA pixels every frame
 	cfg := newPico8Config()
@@ -0,0 +1,101 @@
+package console
+
+import (
+	"image"
+	"math"
+	"sort"
+)
+
+// Poly - draw polygon outline with drawing color, the last point joins the first
+func (p *pixelBuffer) Poly(points []image.Point, colorID ...ColorID) {
+	if len(colorID) == 0 {
+		p.polyWithColor(points, p.fgColor)
+	} else {
+		p.polyWithColor(points, colorID[0])
+	}
+}
+
+// PolyWithColor - draw polygon outline with color
+func (p *pixelBuffer) polyWithColor(points []image.Point, colorID ColorID) {
+	p.fgColor = colorID
+	for i := range points {
+		a := points[i]
+		b := points[(i+1)%len(points)]
+		p.line(a.X-p.camera.x, a.Y-p.camera.y, b.X-p.camera.x, b.Y-p.camera.y, colorID)
+	}
+}
+
+// PolyFill - fill polygon with drawing color, polygons may be concave or self intersecting
+func (p *pixelBuffer) PolyFill(points []image.Point, colorID ...ColorID) {
+	if len(colorID) == 0 {
+		p.polyFillWithColor(points, p.fgColor)
+	} else {
+		p.polyFillWithColor(points, colorID[0])
+	}
+}
+
+// PolyFillWithColor - fill polygon with color
+func (p *pixelBuffer) polyFillWithColor(points []image.Point, colorID ColorID) {
+	if len(points) == 0 {
+		return
+	}
+	p.fgColor = colorID
+
+	minY, maxY := points[0].Y, points[0].Y
+	for _, pt := range points {
+		if pt.Y < minY {
+			minY = pt.Y
+		}
+		if pt.Y > maxY {
+			maxY = pt.Y
+		}
+	}
+
+	// only scanlines inside the clipping rect are filled
+	if top := p.clipRect.Min.Y + p.camera.y; minY < top {
+		minY = top
+	}
+	if bottom := p.clipRect.Max.Y - 1 + p.camera.y; maxY > bottom {
+		maxY = bottom
+	}
+	left := float64(p.clipRect.Min.X + p.camera.x)
+	right := float64(p.clipRect.Max.X - 1 + p.camera.x)
+
+	/* scanline fill with the even-odd rule
+	each scanline is filled between pairs of edge crossings
+	*/
+	xs := make([]float64, 0, len(points))
+	for y := minY; y <= maxY; y++ {
+		xs = xs[:0]
+		for i := range points {
+			a := points[i]
+			b := points[(i+1)%len(points)]
+			// edges include their top but not their bottom so shared vertices cross once
+			if (a.Y <= y && y < b.Y) || (b.Y <= y && y < a.Y) {
+				x := float64(a.X) + float64(y-a.Y)*float64(b.X-a.X)/float64(b.Y-a.Y)
+				xs = append(xs, x)
+			}
+		}
+		sort.Float64s(xs)
+		for i := 0; i+1 < len(xs); i += 2 {
+			x0 := int(math.Ceil(math.Max(xs[i], left)))
+			x1 := int(math.Floor(math.Min(xs[i+1], right)))
+			if x0 <= x1 {
+				p.line(x0-p.camera.x, y-p.camera.y, x1-p.camera.x, y-p.camera.y, colorID)
+			}
+		}
+	}
+
+	// edges are part of the shape so it covers its outline
+	p.polyWithColor(points, colorID)
+}
+
+// Tri - draw triangle outline with drawing color
+func (p *pixelBuffer) Tri(x0, y0, x1, y1, x2, y2 int, colorID ...ColorID) {
+	p.Poly([]image.Point{{X: x0, Y: y0}, {X: x1, Y: y1}, {X: x2, Y: y2}}, colorID...)
+}
+
+// TriFill - fill triangle with drawing color
+func (p *pixelBuffer) TriFill(x0, y0, x1, y1, x2, y2 int, colorID ...ColorID) {
+	p.PolyFill([]image.Point{{X: x0, Y: y0}, {X: x1, Y: y1}, {X: x2, Y: y2}}, colorID...)
+}
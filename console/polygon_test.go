@@ -0,0 +1,85 @@
+package console
+
+import (
+	"image"
+	"testing"
+)
+
+func TestTriFill(t *testing.T) {
+	Init(PICO8)
+	pb := _console.pb
+
+	pb.Cls()
+	pb.TriFill(0, 0, 10, 0, 0, 10, PICO8_RED)
+	for y := 0; y <= 12; y++ {
+		for x := 0; x <= 12; x++ {
+			want := PICO8_BLACK
+			if x+y <= 10 {
+				want = PICO8_RED
+			}
+			if got := pb.PGet(x, y); got != want {
+				t.Fatalf("Expected pixel %d,%d to be: %d got: %d", x, y, want, got)
+			}
+		}
+	}
+
+	// outline matches the edge of the filled triangle
+	pb.Cls()
+	pb.Tri(0, 0, 10, 0, 0, 10, PICO8_GREEN)
+	if pb.PGet(5, 5) != PICO8_GREEN || pb.PGet(2, 2) != PICO8_BLACK || pb.PGet(0, 10) != PICO8_GREEN {
+		t.Errorf("Expected triangle outline only")
+	}
+}
+
+func TestPolyFillConcave(t *testing.T) {
+	Init(PICO8)
+	pb := _console.pb
+
+	// U shape, the gap between the arms is not filled
+	u := []image.Point{{0, 0}, {4, 0}, {4, 6}, {8, 6}, {8, 0}, {12, 0}, {12, 10}, {0, 10}}
+	pb.Cls()
+	pb.Camera(-10, -10)
+	pb.PolyFill(u, PICO8_BLUE)
+	pb.Camera(0, 0)
+
+	tests := []struct {
+		x, y int
+		want ColorID
+	}{
+		{12, 12, PICO8_BLUE},  // left arm
+		{20, 12, PICO8_BLUE},  // right arm
+		{16, 12, PICO8_BLACK}, // gap
+		{16, 18, PICO8_BLUE},  // base
+		{16, 21, PICO8_BLACK}, // below
+	}
+	for _, test := range tests {
+		if got := pb.PGet(test.x, test.y); got != test.want {
+			t.Errorf("Expected pixel %d,%d to be: %d got: %d", test.x, test.y, test.want, got)
+		}
+	}
+}
+
+func TestPolyFillClipped(t *testing.T) {
+	Init(PICO8)
+	pb := _console.pb
+
+	// huge polygon only fills scanlines inside the clip rect
+	pb.Cls()
+	pb.Clip(10, 10, 10, 10)
+	pb.Camera(5, 5)
+	pb.PolyFill([]image.Point{{-1000000, -1000000}, {1000000, -1000000}, {1000000, 1000000}, {-1000000, 1000000}}, PICO8_PINK)
+	pb.Camera(0, 0)
+	pb.Clip(0, 0, 128, 128)
+
+	for y := 0; y < 30; y++ {
+		for x := 0; x < 30; x++ {
+			want := PICO8_BLACK
+			if x >= 10 && x < 20 && y >= 10 && y < 20 {
+				want = PICO8_PINK
+			}
+			if got := pb.PGet(x, y); got != want {
+				t.Fatalf("Expected pixel %d,%d to be: %d got: %d", x, y, want, got)
+			}
+		}
+	}
+}
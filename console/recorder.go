@@ -0,0 +1,121 @@
+package console
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/gif"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// gifFrameSkip - frames are recorded at 30fps, every other console frame
+const gifFrameSkip = 2
+
+// gifDelays - frame delays in 100ths of a second, cycled to average 30fps
+var gifDelays = []int{3, 3, 4}
+
+// recorder - ring buffer of the last few seconds of frames
+type recorder struct {
+	sync.Mutex
+	frames     []*image.Paletted
+	next       int // index of next frame to write
+	count      int // number of frames recorded
+	frameCount int // number of frames offered, used to skip frames
+}
+
+func newRecorder(fps, seconds, width, height int) *recorder {
+	size := fps * seconds / gifFrameSkip
+	if size < 0 {
+		size = 0
+	}
+	r := &recorder{
+		frames: make([]*image.Paletted, size),
+	}
+	rect := image.Rect(0, 0, width, height)
+	for i := range r.frames {
+		r.frames[i] = image.NewPaletted(rect, make(color.Palette, 0, 256))
+	}
+	return r
+}
+
+// AddFrame - copies frame and its current palette into the ring buffer
+func (r *recorder) AddFrame(frame *image.Paletted, palette []color.Color) {
+	r.Lock()
+	defer r.Unlock()
+
+	r.frameCount++
+	if len(r.frames) == 0 || r.frameCount%gifFrameSkip != 0 {
+		return
+	}
+
+	dst := r.frames[r.next]
+	copy(dst.Pix, frame.Pix)
+	dst.Palette = append(dst.Palette[:0], palette...)
+
+	r.next = (r.next + 1) % len(r.frames)
+	if r.count < len(r.frames) {
+		r.count++
+	}
+}
+
+// Video - returns recorded frames encoded as an animated GIF scaled by scale
+func (r *recorder) Video(scale int) ([]byte, error) {
+	r.Lock()
+	defer r.Unlock()
+
+	if r.count == 0 {
+		return nil, fmt.Errorf("Error saving video - no frames recorded")
+	}
+	if scale < 1 {
+		scale = 1
+	}
+
+	anim := &gif.GIF{
+		Image: make([]*image.Paletted, r.count),
+		Delay: make([]int, r.count),
+	}
+	// oldest frame is at next once the ring buffer is full
+	start := (r.next - r.count + len(r.frames)) % len(r.frames)
+	for i := 0; i < r.count; i++ {
+		frame := r.frames[(start+i)%len(r.frames)]
+		anim.Image[i] = scaleFrame(frame, scale)
+		anim.Delay[i] = gifDelays[i%len(gifDelays)]
+	}
+
+	var buf bytes.Buffer
+	if err := gif.EncodeAll(&buf, anim); err != nil {
+		return nil, fmt.Errorf("Error encoding video: %s", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// scaleFrame - returns a copy of frame scaled up by an integer amount
+func scaleFrame(frame *image.Paletted, scale int) *image.Paletted {
+	bounds := frame.Bounds()
+	palette := append(color.Palette(nil), frame.Palette...)
+	scaled := image.NewPaletted(image.Rect(0, 0, bounds.Dx()*scale, bounds.Dy()*scale), palette)
+	for y := 0; y < scaled.Rect.Dy(); y++ {
+		srcRow := frame.Pix[(y/scale)*frame.Stride:]
+		dstRow := scaled.Pix[y*scaled.Stride:]
+		for x := 0; x < scaled.Rect.Dx(); x++ {
+			dstRow[x] = srcRow[x/scale]
+		}
+	}
+	return scaled
+}
+
+// captureFilename - timestamped filename in the capture dir for screenshots, videos & replays
+func (c *console) captureFilename(ext string) string {
+	name := fmt.Sprintf("pico-go-%s%s", time.Now().Format("20060102-150405.000"), ext)
+	c.Lock()
+	defer c.Unlock()
+	return filepath.Join(c.Config.CaptureDir, name)
+}
+
+// SaveVideo - saves a GIF of the last few seconds of the console display
+func SaveVideo() (string, error) {
+	return _console.saveVideo()
+}
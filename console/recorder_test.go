@@ -0,0 +1,53 @@
+package console
+
+import (
+	"bytes"
+	"image"
+	"image/gif"
+	"testing"
+)
+
+func TestRecorderVideo(t *testing.T) {
+	// 1 second at 30fps holds 30 frames
+	pal := newPalette(PICO8)
+	r := newRecorder(60, 1, 8, 4)
+	frame := image.NewPaletted(image.Rect(0, 0, 8, 4), pal.colors)
+	for i := 0; i < 100; i++ {
+		frame.Pix[0] = uint8(i % 16)
+		r.AddFrame(frame, pal.colors)
+	}
+
+	data, err := r.Video(3)
+	if err != nil {
+		t.Fatalf("Failed to encode video: %s", err)
+	}
+	anim, err := gif.DecodeAll(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Failed to decode video: %s", err)
+	}
+
+	if len(anim.Image) != 30 {
+		t.Fatalf("Expected %d frames got: %d", 30, len(anim.Image))
+	}
+	if size := anim.Image[0].Bounds().Size(); size != image.Pt(24, 12) {
+		t.Errorf("Expected scaled size: %v got: %v", image.Pt(24, 12), size)
+	}
+	// every other frame is recorded, the last 30 of frames 1..99 are kept
+	for i, img := range anim.Image {
+		expected := uint8((41 + i*2) % 16)
+		if got := img.ColorIndexAt(0, 0); got != expected {
+			t.Fatalf("Expected frame %d pixel to be: %d got: %d", i, expected, got)
+		}
+		if got := img.ColorIndexAt(2, 2); got != expected {
+			t.Fatalf("Expected scaled frame %d pixel to be: %d got: %d", i, expected, got)
+		}
+	}
+}
+
+func TestRecorderEmpty(t *testing.T) {
+	r := newRecorder(60, 0, 8, 8)
+	r.AddFrame(image.NewPaletted(image.Rect(0, 0, 8, 8), newPalette(PICO8).colors), nil)
+	if _, err := r.Video(1); err == nil {
+		t.Errorf("Expected error saving video with no frames")
+	}
+}
@@ -0,0 +1,268 @@
+package console
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+)
+
+// replayMagic - identifies a replay file and its format version
+const replayMagic = "PGR1"
+
+// _maxReplayFrames - longest replay that is recorded or read, a day at 60 fps
+const _maxReplayFrames = 60 * 60 * 60 * 24
+
+// Replay - rng seed and per frame input of a cart run, replaying it renders
+// the same frames as the original run
+type Replay struct {
+	Seed int64
+	Runs []InputRun // input of consecutive frames, identical frames share a run
+
+	// run last found by Input, frames are usually read in order
+	run      int
+	runStart int
+}
+
+// InputRun - input state repeated for a number of frames
+type InputRun struct {
+	Frames int
+	State  InputState
+}
+
+// Append - adds input of the next frame, extending the last run if input is unchanged
+func (r *Replay) Append(state InputState) {
+	if n := len(r.Runs); n > 0 && r.Runs[n-1].State == state {
+		r.Runs[n-1].Frames++
+		return
+	}
+	r.Runs = append(r.Runs, InputRun{Frames: 1, State: state})
+}
+
+// Len - returns number of frames in the replay
+func (r *Replay) Len() int {
+	frames := 0
+	for _, run := range r.Runs {
+		frames += run.Frames
+	}
+	return frames
+}
+
+// Input - recorded input for frame, no input once the recording has ended
+func (r *Replay) Input(frame int) InputState {
+	if frame < 0 {
+		return InputState{}
+	}
+	if frame < r.runStart || r.run >= len(r.Runs) {
+		r.run, r.runStart = 0, 0
+	}
+	for ; r.run < len(r.Runs); r.run++ {
+		if frame < r.runStart+r.Runs[r.run].Frames {
+			return r.Runs[r.run].State
+		}
+		r.runStart += r.Runs[r.run].Frames
+	}
+	return InputState{}
+}
+
+/*
+	Replay file format, all numbers are varints except the seed:
+
+	magic "PGR1"
+	seed int64 little endian
+	count of runs
+	runs of identical frames:
+		frames in run
+		player 0 & 1 buttons (1 byte each)
+		mouse x, mouse y, mouse buttons, mouse wheel
+
+	Input rarely changes every frame so runs keep files small
+*/
+
+// Write - writes replay in compact run length encoded format
+func (r *Replay) Write(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	bw.WriteString(replayMagic)
+	binary.Write(bw, binary.LittleEndian, r.Seed)
+
+	// empty runs are not written
+	runs := make([]InputRun, 0, len(r.Runs))
+	for _, run := range r.Runs {
+		if run.Frames > 0 {
+			runs = append(runs, run)
+		}
+	}
+
+	buf := make([]byte, binary.MaxVarintLen64)
+	putUvarint := func(v uint64) {
+		bw.Write(buf[:binary.PutUvarint(buf, v)])
+	}
+	putVarint := func(v int64) {
+		bw.Write(buf[:binary.PutVarint(buf, v)])
+	}
+
+	putUvarint(uint64(len(runs)))
+	for _, run := range runs {
+		putUvarint(uint64(run.Frames))
+		bw.Write(run.State.Buttons[:])
+		putVarint(int64(run.State.MouseX))
+		putVarint(int64(run.State.MouseY))
+		putUvarint(uint64(run.State.MouseButtons))
+		putVarint(int64(run.State.MouseWheel))
+	}
+	return bw.Flush()
+}
+
+// ReadReplay - reads a replay written by Replay.Write, replays longer than
+// _maxReplayFrames are rejected
+func ReadReplay(r io.Reader) (*Replay, error) {
+	br := bufio.NewReader(r)
+
+	magic := make([]byte, len(replayMagic))
+	if _, err := io.ReadFull(br, magic); err != nil || string(magic) != replayMagic {
+		return nil, fmt.Errorf("Error reading replay - not a replay file")
+	}
+
+	replay := &Replay{}
+	if err := binary.Read(br, binary.LittleEndian, &replay.Seed); err != nil {
+		return nil, fmt.Errorf("Error reading replay seed: %s", err)
+	}
+
+	// the first read error is kept, later reads are ignored
+	var err error
+	readUvarint := func() uint64 {
+		v, e := binary.ReadUvarint(br)
+		if err == nil {
+			err = e
+		}
+		return v
+	}
+	readVarint := func() int {
+		v, e := binary.ReadVarint(br)
+		if err == nil {
+			err = e
+		}
+		return int(v)
+	}
+
+	// every run has at least 1 frame so the run count is limited too
+	runs := readUvarint()
+	if err == nil && runs > _maxReplayFrames {
+		return nil, fmt.Errorf("Error reading replay - too many runs: %d", runs)
+	}
+	frames := uint64(0)
+	for i := uint64(0); i < runs && err == nil; i++ {
+		var state InputState
+		length := readUvarint()
+		if _, e := io.ReadFull(br, state.Buttons[:]); e != nil && err == nil {
+			err = e
+		}
+		state.MouseX = readVarint()
+		state.MouseY = readVarint()
+		state.MouseButtons = int(readUvarint())
+		state.MouseWheel = readVarint()
+		if err != nil {
+			break
+		}
+		if length == 0 || length > _maxReplayFrames-frames {
+			return nil, fmt.Errorf("Error reading replay - invalid run length: %d", length)
+		}
+		frames += length
+		replay.Runs = append(replay.Runs, InputRun{Frames: int(length), State: state})
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Error reading replay: %s", err)
+	}
+	return replay, nil
+}
+
+// LoadReplay - reads a replay from file
+func LoadReplay(filename string) (*Replay, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("Error opening replay file: %s", err)
+	}
+	defer f.Close()
+	return ReadReplay(f)
+}
+
+// Save - writes replay to file
+func (r *Replay) Save(filename string) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("Error creating replay file: %s", err)
+	}
+	if err := r.Write(f); err != nil {
+		f.Close()
+		return fmt.Errorf("Error writing replay: %s", err)
+	}
+	return f.Close()
+}
+
+// PlayReplay - the next cart loaded uses the replay seed & input instead of live input,
+// live input is restored when the replay ends. nil stops any replay & restores live input
+func PlayReplay(r *Replay) {
+	_console.Lock()
+	defer _console.Unlock()
+	_console.playback = r
+	if r == nil {
+		_console.replay = nil
+		_console.input = nil
+	}
+}
+
+// Recording - returns seed & input recorded since the cart was loaded
+func Recording() *Replay {
+	return _console.getRecording()
+}
+
+func (c *console) getRecording() *Replay {
+	c.Lock()
+	defer c.Unlock()
+	if c.recording == nil {
+		return nil
+	}
+	runs := make([]InputRun, len(c.recording.Runs))
+	copy(runs, c.recording.Runs)
+	return &Replay{Seed: c.recording.Seed, Runs: runs}
+}
+
+// SaveRecording - saves input recorded since the cart was loaded, returns the filename
+func SaveRecording() (string, error) {
+	return _console.saveRecording()
+}
+
+func (c *console) saveRecording() (string, error) {
+	r := c.getRecording()
+	if r == nil {
+		return "", fmt.Errorf("Error saving recording - no cart loaded")
+	}
+	var buf bytes.Buffer
+	if err := r.Write(&buf); err != nil {
+		return "", fmt.Errorf("Error writing replay: %s", err)
+	}
+	filename := c.captureFilename(".pgr")
+	if err := saveCapture(filename, buf.Bytes()); err != nil {
+		return "", err
+	}
+	return filename, nil
+}
+
+// inputState - current button & mouse state
+func (c *console) inputState() InputState {
+	var in InputState
+	for player := range c.buttons.down {
+		for id, down := range c.buttons.down[player] {
+			if down {
+				in.Buttons[player] |= 1 << uint(id)
+			}
+		}
+	}
+	in.MouseX = c.mouse.x
+	in.MouseY = c.mouse.y
+	in.MouseButtons = c.mouse.buttons
+	in.MouseWheel = c.mouse.wheel
+	return in
+}
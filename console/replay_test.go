@@ -0,0 +1,244 @@
+package console
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReplayWriteRead(t *testing.T) {
+	r := &Replay{Seed: -1234567890123}
+	var frames []InputState
+	for i := 0; i < 1000; i++ {
+		in := InputState{MouseX: i / 100, MouseY: -3}
+		if i > 500 {
+			in.Buttons[0] = 1 << BTN_O
+			in.Buttons[1] = 1<<BTN_LEFT | 1<<BTN_X
+			in.MouseButtons = MOUSE_LEFT | MOUSE_MIDDLE
+			in.MouseWheel = -1
+		}
+		r.Append(in)
+		frames = append(frames, in)
+	}
+	// runs of identical input are recorded once
+	if len(r.Runs) != 11 {
+		t.Errorf("Expected 11 runs but got %d", len(r.Runs))
+	}
+
+	buf := &bytes.Buffer{}
+	if err := r.Write(buf); err != nil {
+		t.Fatalf("Failed to write replay: %s", err)
+	}
+	// runs of identical input are stored once
+	if buf.Len() > 200 {
+		t.Errorf("Expected compact replay but got %d bytes", buf.Len())
+	}
+
+	read, err := ReadReplay(buf)
+	if err != nil {
+		t.Fatalf("Failed to read replay: %s", err)
+	}
+	if read.Seed != r.Seed {
+		t.Errorf("Expected seed %d but got %d", r.Seed, read.Seed)
+	}
+	if read.Len() != len(frames) {
+		t.Fatalf("Expected %d frames but got %d", len(frames), read.Len())
+	}
+	for i := range frames {
+		if read.Input(i) != frames[i] {
+			t.Fatalf("Frame %d: expected %+v but got %+v", i, frames[i], read.Input(i))
+		}
+	}
+	// frames can be read out of order
+	if read.Input(3) != frames[3] || read.Input(len(frames)) != (InputState{}) {
+		t.Errorf("Expected input of frame 3 then no input after the replay")
+	}
+
+	if _, err := ReadReplay(bytes.NewBufferString("not a replay")); err == nil {
+		t.Errorf("Expected error reading invalid replay")
+	}
+}
+
+// randomCart - moves a pixel with input and scatters pixels from the console rng
+type randomCart struct {
+	*BaseCartridge
+	x int
+}
+
+func (c *randomCart) Init() error {
+	return nil
+}
+
+func (c *randomCart) Update() {
+	if c.Btn(BTN_RIGHT) {
+		c.x++
+	}
+}
+
+func (c *randomCart) Render() {
+	c.PSet(c.x, c.Stat(STAT_MOUSE_Y), 8)
+	c.PSet(_console.rng.Intn(128), _console.rng.Intn(128), ColorID(_console.rng.Intn(16)))
+}
+
+func TestReplayDeterministic(t *testing.T) {
+	dir, err := ioutil.TempDir("", "replay")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+	defer PlayReplay(nil)
+
+	if err := InitHeadless(PICO8); err != nil {
+		t.Fatalf("Failed to init headless console: %s", err)
+	}
+	SetInput(func(frame int) InputState {
+		in := InputState{MouseY: frame % 7}
+		if frame%3 == 0 {
+			in.Buttons[0] = 1 << BTN_RIGHT
+		}
+		return in
+	})
+	if err := RunHeadless(&randomCart{BaseCartridge: NewBaseCart()}, 100); err != nil {
+		t.Fatalf("Failed to run headless: %s", err)
+	}
+	expected := append([]uint8(nil), GetFrame().Pix...)
+
+	filename := filepath.Join(dir, "test.pgr")
+	if err := Recording().Save(filename); err != nil {
+		t.Fatalf("Failed to save recording: %s", err)
+	}
+
+	// replay on a fresh console
+	replay, err := LoadReplay(filename)
+	if err != nil {
+		t.Fatalf("Failed to load replay: %s", err)
+	}
+	if replay.Len() != 100 {
+		t.Fatalf("Expected 100 recorded frames but got %d", replay.Len())
+	}
+	if err := InitHeadless(PICO8); err != nil {
+		t.Fatalf("Failed to init headless console: %s", err)
+	}
+	PlayReplay(replay)
+	if err := RunHeadless(&randomCart{BaseCartridge: NewBaseCart()}, replay.Len()); err != nil {
+		t.Fatalf("Failed to run headless: %s", err)
+	}
+	if !bytes.Equal(GetFrame().Pix, expected) {
+		t.Errorf("Expected replayed frame to match recorded frame")
+	}
+}
+
+func TestReadReplayLimits(t *testing.T) {
+	header := func() *bytes.Buffer {
+		buf := bytes.NewBufferString(replayMagic)
+		buf.Write(make([]byte, 8))
+		return buf
+	}
+	putUvarint := func(buf *bytes.Buffer, v uint64) {
+		b := make([]byte, binary.MaxVarintLen64)
+		buf.Write(b[:binary.PutUvarint(b, v)])
+	}
+	run := func(buf *bytes.Buffer, length uint64) {
+		putUvarint(buf, length)
+		buf.Write(make([]byte, 6))
+	}
+
+	// more runs than frames allowed
+	buf := header()
+	putUvarint(buf, 1<<62)
+	if _, err := ReadReplay(buf); err == nil {
+		t.Errorf("Expected error reading replay with too many runs")
+	}
+
+	// runs adding up to more frames than allowed
+	buf = header()
+	putUvarint(buf, 2)
+	run(buf, _maxReplayFrames)
+	run(buf, 1)
+	if _, err := ReadReplay(buf); err == nil {
+		t.Errorf("Expected error reading replay longer than %d frames", _maxReplayFrames)
+	}
+
+	buf = header()
+	putUvarint(buf, 1)
+	run(buf, 0)
+	if _, err := ReadReplay(buf); err == nil {
+		t.Errorf("Expected error reading empty run")
+	}
+
+	// the longest replay is kept as a single run
+	buf = header()
+	putUvarint(buf, 1)
+	run(buf, _maxReplayFrames)
+	r, err := ReadReplay(buf)
+	if err != nil {
+		t.Fatalf("Failed to read replay: %s", err)
+	}
+	if len(r.Runs) != 1 || r.Len() != _maxReplayFrames {
+		t.Errorf("Expected 1 run of %d frames but got %d runs", _maxReplayFrames, len(r.Runs))
+	}
+}
+
+func TestReplayEnds(t *testing.T) {
+	defer PlayReplay(nil)
+	if err := InitHeadless(PICO8); err != nil {
+		t.Fatalf("Failed to init headless console: %s", err)
+	}
+
+	r := &Replay{Seed: 1}
+	in := InputState{}
+	in.Buttons[0] = 1 << BTN_RIGHT
+	for i := 0; i < 10; i++ {
+		r.Append(in)
+	}
+	PlayReplay(r)
+	if err := RunHeadless(&randomCart{BaseCartridge: NewBaseCart()}, 5); err != nil {
+		t.Fatalf("Failed to run headless: %s", err)
+	}
+	if _console.playback != nil || _console.input == nil {
+		t.Errorf("Expected replay to be taken by the loaded cart")
+	}
+	if !_console.Btn(BTN_RIGHT) {
+		t.Errorf("Expected replayed button to be down")
+	}
+
+	// live input is restored after the last replayed frame
+	for i := 0; i < 6; i++ {
+		if err := _console.frame(); err != nil {
+			t.Fatalf("Failed to run frame: %s", err)
+		}
+	}
+	if _console.input != nil || _console.Btn(BTN_RIGHT) {
+		t.Errorf("Expected live input after replay ended")
+	}
+
+	// the next cart loaded uses live input
+	if err := RunHeadless(&randomCart{BaseCartridge: NewBaseCart()}, 1); err != nil {
+		t.Fatalf("Failed to run headless: %s", err)
+	}
+	if _console.input != nil || _console.recording.Seed == r.Seed {
+		t.Errorf("Expected replay to be cleared when cart was loaded")
+	}
+}
+
+func TestRecordingRuns(t *testing.T) {
+	defer PlayReplay(nil)
+	if err := InitHeadless(PICO8); err != nil {
+		t.Fatalf("Failed to init headless console: %s", err)
+	}
+	SetInput(func(frame int) InputState {
+		return InputState{MouseX: frame / 50}
+	})
+	if err := RunHeadless(&randomCart{BaseCartridge: NewBaseCart()}, 200); err != nil {
+		t.Fatalf("Failed to run headless: %s", err)
+	}
+
+	// unchanged input extends the last run
+	r := Recording()
+	if len(r.Runs) != 4 || r.Len() != 200 {
+		t.Errorf("Expected 4 runs of 200 frames but got %d runs of %d frames", len(r.Runs), r.Len())
+	}
+}
@@ -0,0 +1,81 @@
+package console
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"log"
+)
+
+// screenshot - copy of the current frame, with border if configured
+func (c *console) screenshot() *image.Paletted {
+	frame := c.pb.GetFrame()
+	palette := append(color.Palette(nil), c.pb.palette.colors...)
+
+	border := 0
+	if c.Config.ScreenshotBorder {
+		border = c.Config.BorderWidth
+	}
+
+	bounds := frame.Bounds()
+	rect := image.Rect(0, 0, bounds.Dx()+border*2, bounds.Dy()+border*2)
+	shot := image.NewPaletted(rect, palette)
+	if border > 0 {
+		borderColor := uint8(c.Config.BorderColor)
+		for i := range shot.Pix {
+			shot.Pix[i] = borderColor
+		}
+	}
+	for y := 0; y < bounds.Dy(); y++ {
+		srcRow := frame.Pix[y*frame.Stride : y*frame.Stride+bounds.Dx()]
+		copy(shot.Pix[(y+border)*shot.Stride+border:], srcRow)
+	}
+	return shot
+}
+
+// screenshotPNG - returns the current frame encoded as a PNG
+func (c *console) screenshotPNG() ([]byte, error) {
+	c.Lock()
+	shot := c.screenshot()
+	scale := c.Config.ScreenshotScale
+	c.Unlock()
+
+	if scale < 1 {
+		scale = 1
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, scaleFrame(shot, scale)); err != nil {
+		return nil, fmt.Errorf("Error encoding screenshot: %s", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// saveScreenshot - saves a PNG of the current frame, returns the filename
+func (c *console) saveScreenshot() (string, error) {
+	data, err := c.screenshotPNG()
+	if err != nil {
+		return "", err
+	}
+	filename := c.captureFilename(".png")
+	if err := saveCapture(filename, data); err != nil {
+		return "", err
+	}
+	return filename, nil
+}
+
+// takeScreenshot - saves a screenshot for the F6 hotkey & editor menu
+func (c *console) takeScreenshot() {
+	if filename, err := c.saveScreenshot(); err != nil {
+		log.Printf("Failed to save screenshot: %s", err)
+	} else {
+		log.Printf("Saved screenshot: %s", filename)
+	}
+}
+
+// SaveScreenshot - saves a PNG of the current console display
+func SaveScreenshot() (string, error) {
+	return _console.saveScreenshot()
+}
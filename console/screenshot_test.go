@@ -0,0 +1,96 @@
+package console
+
+import (
+	"bytes"
+	"image/png"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestScreenshotPNG(t *testing.T) {
+	type screenshotTest struct {
+		consoleType ConsoleType
+		border      int
+		borderColor ColorID
+	}
+
+	tests := []screenshotTest{
+		{consoleType: PICO8, border: 0},
+		{consoleType: ZXSPECTRUM, border: 25, borderColor: ZX_WHITE},
+		{consoleType: CBM64, border: 25, borderColor: C64_LIGHT_BLUE},
+	}
+
+	for _, test := range tests {
+		if err := Init(test.consoleType); err != nil {
+			t.Fatalf("Failed to init %s: %s", test.consoleType, err)
+		}
+		if err := SetScreenshotScale(2); err != nil {
+			t.Fatalf("Failed to set screenshot scale: %s", err)
+		}
+
+		pb := _console.pb
+		pb.Cls(0)
+		pb.PSet(1, 1, 8)
+
+		data, err := _console.screenshotPNG()
+		if err != nil {
+			t.Fatalf("Failed to encode screenshot: %s", err)
+		}
+		img, err := png.Decode(bytes.NewReader(data))
+		if err != nil {
+			t.Fatalf("Failed to decode screenshot: %s", err)
+		}
+
+		w := (_console.Config.ConsoleWidth + test.border*2) * 2
+		h := (_console.Config.ConsoleHeight + test.border*2) * 2
+		if img.Bounds().Dx() != w || img.Bounds().Dy() != h {
+			t.Errorf("%s: expected %dx%d screenshot but got %v", test.consoleType, w, h, img.Bounds())
+		}
+
+		colors := pb.palette.colors
+		// each console pixel is scaled to 2x2
+		offset := test.border * 2
+		if img.At(offset+2, offset+3) != colors[8] {
+			t.Errorf("%s: expected pixel color %v but got %v", test.consoleType, colors[8], img.At(offset+2, offset+3))
+		}
+		if test.border > 0 && img.At(0, 0) != colors[test.borderColor] {
+			t.Errorf("%s: expected border color %v but got %v", test.consoleType, colors[test.borderColor], img.At(0, 0))
+		}
+	}
+}
+
+func TestSaveScreenshot(t *testing.T) {
+	dir, err := ioutil.TempDir("", "screenshot")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+
+	if err := Init(PICO8); err != nil {
+		t.Fatalf("Failed to init console: %s", err)
+	}
+	if _console.Config.CaptureDir == "" {
+		t.Errorf("Expected a default capture dir")
+	}
+	// the capture dir is created when saving
+	dir = filepath.Join(dir, "captures")
+	SetCaptureDir(dir)
+
+	filename, err := SaveScreenshot()
+	if err != nil {
+		t.Fatalf("Failed to save screenshot: %s", err)
+	}
+	if filepath.Dir(filename) != dir || filepath.Ext(filename) != ".png" {
+		t.Errorf("Expected png in capture dir but got %s", filename)
+	}
+	saved, err := ioutil.ReadFile(filename)
+	if err != nil {
+		t.Fatalf("Failed to read screenshot: %s", err)
+	}
+	data, _ := _console.screenshotPNG()
+	if !bytes.Equal(saved, data) {
+		t.Errorf("Expected saved file to be the encoded screenshot")
+	}
+}
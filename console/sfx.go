@@ -0,0 +1,68 @@
+package console
+
+import (
+	"fmt"
+)
+
+// Waveforms - these match the pico8 instrument numbers
+const (
+	WAVE_TRIANGLE = iota
+	WAVE_TILTED_SAW
+	WAVE_SAW
+	WAVE_SQUARE
+	WAVE_PULSE
+	WAVE_ORGAN
+	WAVE_NOISE
+	WAVE_PHASER
+)
+
+// Note effects - these match the pico8 effect numbers
+const (
+	FX_NONE = iota
+	FX_SLIDE
+	FX_VIBRATO
+	FX_DROP
+	FX_FADE_IN
+	FX_FADE_OUT
+	FX_ARP_FAST
+	FX_ARP_SLOW
+)
+
+const (
+	_sfxCount = 64 // number of sfx patterns
+	_sfxNotes = 32 // notes per sfx pattern
+)
+
+// Note - a single note of a sfx pattern
+type Note struct {
+	Pitch    uint8 // 0-63, C0 to D#5, 33 is A2 (440Hz)
+	Waveform uint8 // 0-7
+	Volume   uint8 // 0-7, 0 is silent
+	Effect   uint8 // 0-7
+}
+
+// SfxPattern - 32 notes played at speed ticks per note
+// when LoopEnd is greater than LoopStart the notes in between repeat
+type SfxPattern struct {
+	Notes     [_sfxNotes]Note
+	Speed     uint8 // ticks per note, a tick is 1/120th of a second
+	LoopStart uint8
+	LoopEnd   uint8
+}
+
+// Sfx - plays sfx n on channel (-1 picks a free channel) starting at note offset
+// n = -1 stops the channel (-1 for all channels), n = -2 releases a looping sfx
+func (c *console) Sfx(n, channel, offset int) {
+	if c.synth == nil {
+		return
+	}
+	c.synth.play(n, channel, offset)
+}
+
+// SetSfx - defines sfx pattern n
+func (c *console) SetSfx(n int, sfx SfxPattern) error {
+	if c.synth == nil {
+		return fmt.Errorf("Error setting sfx - audio not initialised")
+	}
+	return c.synth.setSfx(n, sfx)
+}
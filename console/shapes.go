@@ -0,0 +1,191 @@
+package console
+
+// Oval - draw ellipse inside bounding box with drawing color
+func (p *pixelBuffer) Oval(x0, y0, x1, y1 int, colorID ...ColorID) {
+	if len(colorID) == 0 {
+		p.ovalWithColor(x0, y0, x1, y1, p.fgColor, false)
+	} else {
+		p.ovalWithColor(x0, y0, x1, y1, colorID[0], false)
+	}
+}
+
+// OvalFill - fill ellipse inside bounding box with drawing color
+func (p *pixelBuffer) OvalFill(x0, y0, x1, y1 int, colorID ...ColorID) {
+	if len(colorID) == 0 {
+		p.ovalWithColor(x0, y0, x1, y1, p.fgColor, true)
+	} else {
+		p.ovalWithColor(x0, y0, x1, y1, colorID[0], true)
+	}
+}
+
+// OvalWithColor - draw or fill ellipse inside bounding box with color
+func (p *pixelBuffer) ovalWithColor(x0, y0, x1, y1 int, colorID ColorID, fill bool) {
+	p.fgColor = colorID
+	x0, y0, x1, y1 = sortBox(x0-p.camera.x, y0-p.camera.y, x1-p.camera.x, y1-p.camera.y)
+
+	// boxes 1 or 2 pixels high are filled
+	if y1-y0 < 2 {
+		for y := y0; y <= y1; y++ {
+			p.line(x0, y, x1, y, colorID)
+		}
+		return
+	}
+
+	// odd sizes have 2 centers a pixel apart so the oval fills the box
+	a := (x1 - x0) / 2
+	b := (y1 - y0) / 2
+	l, t := x0+a, y0+b
+	r, bt := x1-a, y1-b
+
+	// midpoint ellipse, decision values are scaled by 4 to stay integer
+	a2, b2 := a*a, b*b
+	x := 0
+	y := b
+	dx := 0
+	dy := 2 * a2 * y
+	p0 := 4*b2 - 4*a2*b + a2
+
+	// region 1, slope shallower than -1
+	for dx < dy {
+		p.ovalPoints(l, t, r, bt, x, y, colorID, fill)
+		x++
+		dx += 2 * b2
+		if p0 < 0 {
+			p0 += 4 * (dx + b2)
+		} else {
+			y--
+			dy -= 2 * a2
+			p0 += 4 * (dx - dy + b2)
+		}
+	}
+
+	// region 2, slope steeper than -1
+	p0 = b2*(2*x+1)*(2*x+1) + 4*a2*(y-1)*(y-1) - 4*a2*b2
+	for y >= 0 {
+		p.ovalPoints(l, t, r, bt, x, y, colorID, fill)
+		y--
+		dy -= 2 * a2
+		if p0 > 0 {
+			p0 += 4 * (a2 - dy)
+		} else {
+			x++
+			dx += 2 * b2
+			p0 += 4 * (dx - dy + a2)
+		}
+	}
+}
+
+// ovalPoints - plots quadrant points x, y from centers left, top, right, bottom
+// or fills lines between them
+func (p *pixelBuffer) ovalPoints(l, t, r, b, x, y int, colorID ColorID, fill bool) {
+	if fill {
+		p.line(l-x, t-y, r+x, t-y, colorID)
+		p.line(l-x, b+y, r+x, b+y, colorID)
+		return
+	}
+	p.setPixel(r+x, b+y, colorID)
+	p.setPixel(l-x, b+y, colorID)
+	p.setPixel(r+x, t-y, colorID)
+	p.setPixel(l-x, t-y, colorID)
+}
+
+// RoundRect - draw rectangle with corners of radius r with drawing color
+func (p *pixelBuffer) RoundRect(x0, y0, x1, y1, r int, colorID ...ColorID) {
+	if len(colorID) == 0 {
+		p.roundRectWithColor(x0, y0, x1, y1, r, p.fgColor, false)
+	} else {
+		p.roundRectWithColor(x0, y0, x1, y1, r, colorID[0], false)
+	}
+}
+
+// RoundRectFill - fill rectangle with corners of radius r with drawing color
+func (p *pixelBuffer) RoundRectFill(x0, y0, x1, y1, r int, colorID ...ColorID) {
+	if len(colorID) == 0 {
+		p.roundRectWithColor(x0, y0, x1, y1, r, p.fgColor, true)
+	} else {
+		p.roundRectWithColor(x0, y0, x1, y1, r, colorID[0], true)
+	}
+}
+
+// RoundRectWithColor - draw or fill rectangle with rounded corners with color
+func (p *pixelBuffer) roundRectWithColor(x0, y0, x1, y1, rad int, colorID ColorID, fill bool) {
+	p.fgColor = colorID
+	x0, y0, x1, y1 = sortBox(x0-p.camera.x, y0-p.camera.y, x1-p.camera.x, y1-p.camera.y)
+
+	// radius is limited so the corners meet
+	if rad > (x1-x0)/2 {
+		rad = (x1 - x0) / 2
+	}
+	if rad > (y1-y0)/2 {
+		rad = (y1 - y0) / 2
+	}
+	if rad < 0 {
+		rad = 0
+	}
+
+	// corner centers
+	l, t := x0+rad, y0+rad
+	r, b := x1-rad, y1-rad
+
+	// straight edges
+	if fill {
+		for y := t; y <= b; y++ {
+			p.line(x0, y, x1, y, colorID)
+		}
+	} else {
+		p.line(l, y0, r, y0, colorID)
+		p.line(l, y1, r, y1, colorID)
+		p.line(x0, t, x0, b, colorID)
+		p.line(x1, t, x1, b, colorID)
+	}
+
+	/* corners use the same circle calcs as circleWithColor
+	http://xiaohuiliucuriosity.blogspot.co.uk/2015/03/draw-circle-using-integer-arithmetic.html
+	*/
+	x := 0
+	y := rad
+	p0 := (5 - rad*4) / 4
+
+	p.cornerPoints(l, t, r, b, x, y, colorID, fill)
+	for x < y {
+		x++
+		if p0 < 0 {
+			p0 += 2*x + 1
+		} else {
+			y--
+			p0 += 2*(x-y) + 1
+		}
+		p.cornerPoints(l, t, r, b, x, y, colorID, fill)
+	}
+}
+
+// cornerPoints - plots octant points x, y around corner centers left, top, right, bottom
+// or fills lines between them
+func (p *pixelBuffer) cornerPoints(l, t, r, b, x, y int, colorID ColorID, fill bool) {
+	if fill {
+		p.line(l-x, b+y, r+x, b+y, colorID)
+		p.line(l-x, t-y, r+x, t-y, colorID)
+		p.line(l-y, b+x, r+y, b+x, colorID)
+		p.line(l-y, t-x, r+y, t-x, colorID)
+		return
+	}
+	p.setPixel(r+x, b+y, colorID)
+	p.setPixel(l-x, b+y, colorID)
+	p.setPixel(r+x, t-y, colorID)
+	p.setPixel(l-x, t-y, colorID)
+	p.setPixel(r+y, b+x, colorID)
+	p.setPixel(l-y, b+x, colorID)
+	p.setPixel(r+y, t-x, colorID)
+	p.setPixel(l-y, t-x, colorID)
+}
+
+// sortBox - returns box corners ordered top left then bottom right
+func sortBox(x0, y0, x1, y1 int) (int, int, int, int) {
+	if x0 > x1 {
+		x0, x1 = x1, x0
+	}
+	if y0 > y1 {
+		y0, y1 = y1, y0
+	}
+	return x0, y0, x1, y1
+}
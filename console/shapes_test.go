@@ -0,0 +1,120 @@
+package console
+
+import (
+	"testing"
+)
+
+// bounds - returns box around pixels that are not color 0
+func bounds(pb *pixelBuffer) (x0, y0, x1, y1 int) {
+	x0, y0, x1, y1 = pb.GetWidth(), pb.GetHeight(), -1, -1
+	for y := 0; y < pb.GetHeight(); y++ {
+		for x := 0; x < pb.GetWidth(); x++ {
+			if pb.PGet(x, y) == 0 {
+				continue
+			}
+			if x < x0 {
+				x0 = x
+			}
+			if y < y0 {
+				y0 = y
+			}
+			if x > x1 {
+				x1 = x
+			}
+			if y > y1 {
+				y1 = y
+			}
+		}
+	}
+	return
+}
+
+func TestOvalBounds(t *testing.T) {
+	Init(PICO8)
+	pb := _console.pb
+
+	boxes := [][4]int{{10, 20, 50, 40}, {10, 20, 51, 41}, {50, 40, 10, 20}, {5, 5, 6, 30}, {5, 5, 30, 5}}
+	for _, box := range boxes {
+		for _, fill := range []bool{false, true} {
+			pb.Cls()
+			if fill {
+				pb.OvalFill(box[0], box[1], box[2], box[3], PICO8_RED)
+			} else {
+				pb.Oval(box[0], box[1], box[2], box[3], PICO8_RED)
+			}
+			x0, y0, x1, y1 := sortBox(box[0], box[1], box[2], box[3])
+			if bx0, by0, bx1, by1 := bounds(pb); bx0 != x0 || by0 != y0 || bx1 != x1 || by1 != y1 {
+				t.Errorf("Expected oval %v fill %t to fill box got: %d,%d,%d,%d", box, fill, bx0, by0, bx1, by1)
+			}
+		}
+	}
+
+	// corners of the box are outside the oval, the center is only filled by OvalFill
+	pb.Cls()
+	pb.Oval(10, 20, 50, 40, PICO8_RED)
+	if pb.PGet(10, 20) != 0 || pb.PGet(30, 30) != 0 || pb.PGet(30, 20) != PICO8_RED {
+		t.Errorf("Expected oval outline")
+	}
+	pb.OvalFill(10, 20, 50, 40, PICO8_RED)
+	if pb.PGet(10, 20) != 0 || pb.PGet(30, 30) != PICO8_RED {
+		t.Errorf("Expected filled oval")
+	}
+}
+
+func TestOvalMatchesCircle(t *testing.T) {
+	Init(PICO8)
+	pb := _console.pb
+
+	// a square oval covers the same rows as a circle of the same size
+	pb.Cls()
+	pb.CircleFill(64, 64, 20, PICO8_RED)
+	cx0, cy0, cx1, cy1 := bounds(pb)
+	pb.Cls()
+	pb.OvalFill(44, 44, 84, 84, PICO8_RED)
+	if x0, y0, x1, y1 := bounds(pb); x0 != cx0 || y0 != cy0 || x1 != cx1 || y1 != cy1 {
+		t.Errorf("Expected oval bounds %d,%d,%d,%d got: %d,%d,%d,%d", cx0, cy0, cx1, cy1, x0, y0, x1, y1)
+	}
+}
+
+func TestRoundRect(t *testing.T) {
+	Init(PICO8)
+	pb := _console.pb
+
+	// zero radius is the same as a rect
+	pb.Cls()
+	pb.Rect(10, 10, 40, 30, PICO8_RED)
+	expected := make([]uint8, len(pb.pixelSurface.Pix))
+	copy(expected, pb.pixelSurface.Pix)
+	pb.Cls()
+	pb.RoundRect(10, 10, 40, 30, 0, PICO8_RED)
+	for i := range expected {
+		if expected[i] != pb.pixelSurface.Pix[i] {
+			t.Fatalf("Expected round rect with no radius to match rect")
+		}
+	}
+
+	for _, fill := range []bool{false, true} {
+		pb.Cls()
+		if fill {
+			pb.RoundRectFill(10, 10, 40, 30, 6, PICO8_GREEN)
+		} else {
+			pb.RoundRect(10, 10, 40, 30, 6, PICO8_GREEN)
+		}
+		if x0, y0, x1, y1 := bounds(pb); x0 != 10 || y0 != 10 || x1 != 40 || y1 != 30 {
+			t.Errorf("Expected round rect fill %t to fill box got: %d,%d,%d,%d", fill, x0, y0, x1, y1)
+		}
+		if pb.PGet(10, 10) != 0 || pb.PGet(40, 30) != 0 || pb.PGet(25, 10) != PICO8_GREEN || pb.PGet(10, 20) != PICO8_GREEN {
+			t.Errorf("Expected round rect fill %t to have rounded corners & straight edges", fill)
+		}
+		if filled := pb.PGet(25, 20) == PICO8_GREEN; filled != fill {
+			t.Errorf("Expected round rect center filled: %t", fill)
+		}
+	}
+
+	// radius is limited to half the smallest side
+	pb.Cls()
+	pb.RoundRectFill(0, 0, 10, 4, 20, PICO8_GREEN)
+	if x0, y0, x1, y1 := bounds(pb); x0 != 0 || y0 != 0 || x1 != 10 || y1 != 4 {
+		t.Errorf("Expected large radius to fit box got: %d,%d,%d,%d", x0, y0, x1, y1)
+	}
+}
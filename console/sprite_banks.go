@@ -0,0 +1,100 @@
+package console
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"io/ioutil"
+)
+
+/*
+	Sprite banks - each bank is a sprite sheet of color ids
+
+	Bank n is stored at _console.sprites[n] with its own sprite flags in
+	_console.spriteFlags[n]. Bank 0 is the built in sprite sheet, carts can
+	load more from png images which are quantized to the drawable console colors.
+
+	Sprites are drawn by color id, so the draw palette and transparency
+	apply to every bank.
+*/
+
+// spriteSheet - returns the sprite sheet of the current bank
+func spriteSheet() *image.Paletted {
+	return _console.sprites[_console.currentSpriteBank]
+}
+
+// bankFlags - returns the sprite flags of the current bank
+func bankFlags() []uint8 {
+	return _console.spriteFlags[_console.currentSpriteBank]
+}
+
+// spritesPerLine - returns how many sprites are on each line of the current bank
+func spritesPerLine() int {
+	return spriteSheet().Bounds().Dx() / _spriteWidth
+}
+
+// addSpriteBank - adds sprite sheet as a new bank, returns bank number
+func addSpriteBank(sheet *image.Paletted) int {
+	// pixels are color ids of the console palette
+	sheet.Palette = _console.palette.originalColors
+	_console.sprites = append(_console.sprites, sheet)
+
+	// one byte of flags per sprite on the sheet
+	bounds := sheet.Bounds()
+	totalSprites := (bounds.Dx() / _spriteWidth) * (bounds.Dy() / _spriteHeight)
+	_console.spriteFlags = append(_console.spriteFlags, make([]uint8, totalSprites))
+	return len(_console.sprites) - 1
+}
+
+// quantize - converts image to nearest colors of the palette, transparent pixels become color 0
+func quantize(img image.Image, colors color.Palette) *image.Paletted {
+	bounds := img.Bounds()
+	sheet := image.NewPaletted(image.Rect(0, 0, bounds.Dx(), bounds.Dy()), nil)
+	for y := 0; y < bounds.Dy(); y++ {
+		for x := 0; x < bounds.Dx(); x++ {
+			c := img.At(bounds.Min.X+x, bounds.Min.Y+y)
+			if _, _, _, a := c.RGBA(); a < 0x8000 {
+				continue
+			}
+			sheet.Pix[y*sheet.Stride+x] = uint8(colors.Index(c))
+		}
+	}
+	return sheet
+}
+
+// LoadSprites - adds a sprite bank from png image data, returns the bank number
+func (p *pixelBuffer) LoadSprites(data []byte) (int, error) {
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return 0, fmt.Errorf("Error loading sprites: %s", err)
+	}
+	bounds := img.Bounds()
+	if bounds.Dx() < _spriteWidth || bounds.Dy() < _spriteHeight {
+		return 0, fmt.Errorf("Error loading sprites - image smaller than a sprite: %dx%d", bounds.Dx(), bounds.Dy())
+	}
+	return addSpriteBank(quantize(img, p.palette.drawColors())), nil
+}
+
+// LoadSpritesFile - adds a sprite bank from a png file, returns the bank number
+func (p *pixelBuffer) LoadSpritesFile(filename string) (int, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return 0, fmt.Errorf("Error loading sprites: %s", err)
+	}
+	return p.LoadSprites(data)
+}
+
+// SetSpriteBank - selects bank used by sprite & map drawing and sprite memory
+func (p *pixelBuffer) SetSpriteBank(bank int) error {
+	if bank < 0 || bank >= len(_console.sprites) {
+		return fmt.Errorf("Error selecting sprite bank - bank outside range: %d", bank)
+	}
+	_console.currentSpriteBank = bank
+	return nil
+}
+
+// GetSpriteBank - returns bank used for drawing
+func (p *pixelBuffer) GetSpriteBank() int {
+	return _console.currentSpriteBank
+}
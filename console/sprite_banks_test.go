@@ -0,0 +1,123 @@
+package console
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/png"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadSprites(t *testing.T) {
+	Init(PICO8)
+	pb := _console.pb
+
+	// 2 sprites wide, second sprite has a red, a nearly orange & a transparent pixel
+	img := image.NewRGBA(image.Rect(0, 0, 16, 8))
+	img.Set(8, 0, color.RGBA{R: 255, G: 0, B: 77, A: 255})
+	img.Set(9, 0, color.RGBA{R: 250, G: 160, B: 10, A: 255})
+	img.Set(10, 0, color.RGBA{R: 255, G: 255, B: 255, A: 0})
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("Failed to encode png: %s", err)
+	}
+
+	bank, err := pb.LoadSprites(buf.Bytes())
+	if err != nil {
+		t.Fatalf("Failed to load sprites: %s", err)
+	}
+	if bank != 1 {
+		t.Errorf("Expected bank 1 got: %d", bank)
+	}
+	if err := pb.SetSpriteBank(bank); err != nil {
+		t.Fatalf("Failed to select sprite bank: %s", err)
+	}
+
+	pb.Cls(PICO8_BLUE)
+	pb.Sprite(1, 0, 0, 1, 1, 8, 8)
+	expected := []ColorID{PICO8_RED, PICO8_ORANGE, PICO8_BLUE}
+	for x, want := range expected {
+		if got := pb.PGet(x, 0); got != want {
+			t.Errorf("Expected pixel %d to be: %d got: %d", x, want, got)
+		}
+	}
+
+	// sprite memory is the selected bank
+	if pb.Peek(MEM_SPRITES+4) != uint8(PICO8_ORANGE)<<4|uint8(PICO8_RED) {
+		t.Errorf("Expected bank pixels in sprite memory got: %#x", pb.Peek(MEM_SPRITES+4))
+	}
+
+	if err := pb.SetSpriteBank(2); err == nil {
+		t.Errorf("Expected error selecting missing bank")
+	}
+	if err := pb.SetSpriteBank(0); err != nil || pb.GetSpriteBank() != 0 {
+		t.Errorf("Expected to select bank 0 got: %d %v", pb.GetSpriteBank(), err)
+	}
+}
+
+func TestLoadSpritesErrors(t *testing.T) {
+	Init(PICO8)
+	pb := _console.pb
+
+	if _, err := pb.LoadSprites([]byte("not a png")); err == nil {
+		t.Errorf("Expected error loading invalid png")
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 4, 4))); err != nil {
+		t.Fatalf("Failed to encode png: %s", err)
+	}
+	if _, err := pb.LoadSprites(buf.Bytes()); err == nil {
+		t.Errorf("Expected error loading image smaller than a sprite")
+	}
+
+	dir, err := ioutil.TempDir("", "sprites")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %s", err)
+	}
+	defer os.RemoveAll(dir)
+	if _, err := pb.LoadSpritesFile(filepath.Join(dir, "missing.png")); err == nil {
+		t.Errorf("Expected error loading missing file")
+	}
+}
+
+func TestSpriteBankFlags(t *testing.T) {
+	Init(PICO8)
+	pb := _console.pb
+
+	// bank of 2 sprites
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 16, 8))); err != nil {
+		t.Fatalf("Failed to encode png: %s", err)
+	}
+	bank, err := pb.LoadSprites(buf.Bytes())
+	if err != nil {
+		t.Fatalf("Failed to load sprites: %s", err)
+	}
+
+	pb.FSet(1, 2, true)
+	if err := pb.SetSpriteBank(bank); err != nil {
+		t.Fatalf("Failed to select sprite bank: %s", err)
+	}
+	if pb.FGet(1, 2) {
+		t.Errorf("Expected flags of bank 0 not to apply to bank %d", bank)
+	}
+	pb.FSet(1, 0, true)
+	pb.FSet(200, 0, true)
+	if !pb.FGet(1, 0) || pb.FGet(200, 0) {
+		t.Errorf("Expected only flags of sprites on the bank")
+	}
+	if pb.Peek(MEM_SPRITE_FLAGS+1) != 0x01 || pb.Peek(MEM_SPRITE_FLAGS+200) != 0 {
+		t.Errorf("Expected bank flags in memory got: %#x", pb.Peek(MEM_SPRITE_FLAGS+1))
+	}
+
+	if err := pb.SetSpriteBank(0); err != nil {
+		t.Fatalf("Failed to select sprite bank: %s", err)
+	}
+	if !pb.FGet(1, 2) || pb.FGet(1, 0) {
+		t.Errorf("Expected bank 0 flags got: %#x", pb.Peek(MEM_SPRITE_FLAGS+1))
+	}
+}
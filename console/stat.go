@@ -0,0 +1,33 @@
+package console
+
+// Stat IDs - these match the pico8 stat numbers
+const (
+	STAT_MUSIC_PATTERN = 24 // music pattern playing, -1 when stopped
+	STAT_MOUSE_X       = 32 // mouse x in console pixels
+	STAT_MOUSE_Y       = 33 // mouse y in console pixels
+	STAT_MOUSE_BUTTONS = 34 // mouse button bitmask
+	STAT_MOUSE_WHEEL   = 36 // mouse wheel delta this frame -1, 0 or 1
+)
+
+// Stat - returns console state such as mouse position
+func (c *console) Stat(n int) int {
+	if n == STAT_MUSIC_PATTERN {
+		c.synth.Lock()
+		defer c.synth.Unlock()
+		return c.synth.musicState.pattern
+	}
+
+	c.Lock()
+	defer c.Unlock()
+	switch n {
+	case STAT_MOUSE_X:
+		return c.mouse.x
+	case STAT_MOUSE_Y:
+		return c.mouse.y
+	case STAT_MOUSE_BUTTONS:
+		return c.mouse.buttons
+	case STAT_MOUSE_WHEEL:
+		return c.mouse.wheel
+	}
+	return 0
+}
@@ -0,0 +1,319 @@
+package console
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"sync"
+)
+
+const (
+	_sampleRate     = 44100
+	_samplesPerTick = 366 // a pico8 tick is 183 samples at 22050Hz
+	_audioChannels  = 4
+	_masterVolume   = 0.5
+	_vibratoHz      = 7.5
+)
+
+// relative loudness of each waveform, square waves sound much louder than triangles
+var waveGain = [8]float64{
+	WAVE_TRIANGLE:   0.5,
+	WAVE_TILTED_SAW: 0.5,
+	WAVE_SAW:        0.4,
+	WAVE_SQUARE:     0.25,
+	WAVE_PULSE:      0.25,
+	WAVE_ORGAN:      0.45,
+	WAVE_NOISE:      0.3,
+	WAVE_PHASER:     0.45,
+}
+
+// synth - software synthesizer mixing sfx on 4 channels into 16 bit PCM
+type synth struct {
+	sync.Mutex
+	sfx        [_sfxCount]SfxPattern
+	music      [_musicCount]MusicPattern
+	channels   [_audioChannels]sfxChannel
+	musicState musicState
+	noise      uint32  // noise generator state, fixed seed keeps output deterministic
+	buf        []int16 // reused by Read
+}
+
+// sfxChannel - playback state of a single channel
+type sfxChannel struct {
+	sfx        int     // sfx playing, -1 when idle
+	note       int     // index of note playing
+	sample     int     // sample position within current note
+	phase      float64 // waveform position in cycles
+	noiseValue float64 // current sample and hold noise value
+	prevPitch  float64 // pitch of previous note used by slide
+	prevVolume float64 // volume of previous note used by slide
+	released   bool    // loop released, sfx plays to the end
+	music      bool    // channel is being played by the music sequencer
+}
+
+func newSynth() *synth {
+	s := &synth{
+		noise: 1,
+	}
+	for i := range s.channels {
+		s.channels[i].sfx = -1
+	}
+	for i := range s.music {
+		s.music[i] = NewMusicPattern()
+	}
+	s.stopMusic()
+	return s
+}
+
+func (s *synth) setSfx(n int, sfx SfxPattern) error {
+	if n < 0 || n >= _sfxCount {
+		return fmt.Errorf("Error setting sfx - number outside range: %d", n)
+	}
+	s.Lock()
+	defer s.Unlock()
+	s.sfx[n] = sfx
+	return nil
+}
+
+// play - starts, stops or releases sfx, see console.Sfx
+func (s *synth) play(n, channel, offset int) {
+	s.Lock()
+	defer s.Unlock()
+
+	switch {
+	case n == -1:
+		s.forChannels(channel, func(ch *sfxChannel) { ch.sfx = -1 })
+		return
+	case n == -2:
+		s.forChannels(channel, func(ch *sfxChannel) { ch.released = true })
+		return
+	case n < 0 || n >= _sfxCount:
+		return
+	}
+
+	if channel < 0 {
+		channel = s.freeChannel(n)
+	}
+	if channel >= _audioChannels {
+		return
+	}
+	s.startSfx(channel, n, offset)
+}
+
+// startSfx - starts sfx n on channel from note offset
+func (s *synth) startSfx(channel, n, offset int) {
+	if offset < 0 || offset >= _sfxNotes {
+		offset = 0
+	}
+	s.channels[channel] = sfxChannel{
+		sfx:       n,
+		note:      offset,
+		prevPitch: float64(s.sfx[n].Notes[offset].Pitch),
+	}
+}
+
+// forChannels - calls f for channel, or every channel when channel is -1
+func (s *synth) forChannels(channel int, f func(ch *sfxChannel)) {
+	for i := range s.channels {
+		if channel < 0 || channel == i {
+			f(&s.channels[i])
+		}
+	}
+}
+
+// freeChannel - channel already playing sfx n, else first idle channel,
+// else lowest channel not playing music, channels reserved for music are skipped
+func (s *synth) freeChannel(n int) int {
+	for i, ch := range s.channels {
+		if ch.sfx == n && !ch.music {
+			return i
+		}
+	}
+	for i, ch := range s.channels {
+		if ch.sfx < 0 && !s.isReserved(i) {
+			return i
+		}
+	}
+	for i, ch := range s.channels {
+		if !ch.music && !s.isReserved(i) {
+			return i
+		}
+	}
+	return _audioChannels
+}
+
+// isReserved - true if channel is reserved for music
+func (s *synth) isReserved(channel int) bool {
+	return s.musicState.pattern >= 0 && s.musicState.reserved&(1<<uint(channel)) != 0
+}
+
+// render - mixes all channels into buf as mono samples
+func (s *synth) render(buf []int16) {
+	s.Lock()
+	defer s.Unlock()
+
+	for i := range buf {
+		mix := 0.0
+		for c := range s.channels {
+			sample := s.nextSample(&s.channels[c])
+			if s.channels[c].music {
+				sample *= s.musicState.gain
+			}
+			mix += sample
+		}
+		s.fadeMusic()
+		mix *= _masterVolume
+		if mix > 1 {
+			mix = 1
+		} else if mix < -1 {
+			mix = -1
+		}
+		buf[i] = int16(mix * math.MaxInt16)
+	}
+}
+
+// Read - implements io.Reader, fills b with 16 bit little endian stereo samples for the audio device
+func (s *synth) Read(b []byte) (int, error) {
+	frames := len(b) / 4
+	if cap(s.buf) < frames {
+		s.buf = make([]int16, frames)
+	}
+	s.buf = s.buf[:frames]
+	s.render(s.buf)
+	for i, sample := range s.buf {
+		binary.LittleEndian.PutUint16(b[i*4:], uint16(sample))
+		binary.LittleEndian.PutUint16(b[i*4+2:], uint16(sample))
+	}
+	return frames * 4, nil
+}
+
+// Close - implements io.Closer
+func (s *synth) Close() error {
+	return nil
+}
+
+// nextSample - returns next sample of channel and advances it
+func (s *synth) nextSample(ch *sfxChannel) float64 {
+	if ch.sfx < 0 {
+		return 0
+	}
+	pattern := &s.sfx[ch.sfx]
+	speed := int(pattern.Speed)
+	if speed < 1 {
+		speed = 1
+	}
+	noteLen := speed * _samplesPerTick
+	note := pattern.Notes[ch.note]
+
+	// progress through note 0..1
+	f := float64(ch.sample) / float64(noteLen)
+	pitch := float64(note.Pitch)
+	volume := float64(note.Volume) / 7
+
+	switch note.Effect {
+	case FX_SLIDE:
+		pitch = ch.prevPitch + (pitch-ch.prevPitch)*f
+		volume = ch.prevVolume + (volume-ch.prevVolume)*f
+	case FX_VIBRATO:
+		t := float64(ch.sample) / _sampleRate
+		pitch += 0.5 * math.Sin(2*math.Pi*_vibratoHz*t)
+	case FX_DROP:
+		pitch *= 1 - f
+	case FX_FADE_IN:
+		volume *= f
+	case FX_FADE_OUT:
+		volume *= 1 - f
+	case FX_ARP_FAST, FX_ARP_SLOW:
+		// cycle through the group of 4 notes this note belongs to
+		ticksPerStep := 2
+		if note.Effect == FX_ARP_SLOW {
+			ticksPerStep = 4
+		}
+		step := (ch.sample / (_samplesPerTick * ticksPerStep)) % 4
+		pitch = float64(pattern.Notes[ch.note&^3+step].Pitch)
+	}
+
+	out := 0.0
+	if volume > 0 {
+		out = s.waveform(ch, note.Waveform) * volume * waveGain[note.Waveform%8]
+	}
+
+	// advance waveform, phaser needs phase over 128 cycles
+	freq := 440 * math.Pow(2, (pitch-33)/12)
+	prevPhase := ch.phase
+	ch.phase = math.Mod(ch.phase+freq/_sampleRate, 128)
+	if math.Floor(prevPhase*16) != math.Floor(ch.phase*16) {
+		ch.noiseValue = s.nextNoise()
+	}
+
+	ch.sample++
+	if ch.sample >= noteLen {
+		ch.sample = 0
+		ch.prevPitch = float64(note.Pitch)
+		ch.prevVolume = float64(note.Volume) / 7
+		ch.nextNote(pattern)
+	}
+	return out
+}
+
+// nextNote - moves to next note, looping if required
+func (ch *sfxChannel) nextNote(pattern *SfxPattern) {
+	ch.note++
+	if !ch.released && pattern.LoopEnd > pattern.LoopStart && ch.note >= int(pattern.LoopEnd) {
+		ch.note = int(pattern.LoopStart)
+	}
+	if ch.note >= _sfxNotes {
+		ch.sfx = -1
+	}
+}
+
+// waveform - returns value of waveform at current phase of channel in range -1..1
+func (s *synth) waveform(ch *sfxChannel, waveform uint8) float64 {
+	t := ch.phase - math.Floor(ch.phase)
+	switch waveform {
+	case WAVE_TRIANGLE:
+		return triangle(t)
+	case WAVE_TILTED_SAW:
+		// rises for most of the cycle then falls sharply
+		const peak = 0.875
+		if t < peak {
+			return 2*t/peak - 1
+		}
+		return 2*(1-t)/(1-peak) - 1
+	case WAVE_SAW:
+		return 2*t - 1
+	case WAVE_SQUARE:
+		if t < 0.5 {
+			return 1
+		}
+		return -1
+	case WAVE_PULSE:
+		if t < 1.0/3 {
+			return 1
+		}
+		return -1
+	case WAVE_ORGAN:
+		// fundamental mixed with octave above
+		return (triangle(t) + triangle(math.Mod(t*2, 1))) / 2
+	case WAVE_NOISE:
+		return ch.noiseValue
+	case WAVE_PHASER:
+		// two triangles drifting in and out of phase
+		k := math.Abs(2*(ch.phase/128) - 1)
+		u := math.Mod(t+0.5*k, 1)
+		return (triangle(t) + triangle(u)) / 2
+	}
+	return 0
+}
+
+func triangle(t float64) float64 {
+	return math.Abs(4*t-2) - 1
+}
+
+// nextNoise - xorshift random number in range -1..1
+func (s *synth) nextNoise() float64 {
+	s.noise ^= s.noise << 13
+	s.noise ^= s.noise >> 17
+	s.noise ^= s.noise << 5
+	return float64(s.noise)/math.MaxUint32*2 - 1
+}
@@ -0,0 +1,139 @@
+package console
+
+import (
+	"encoding/binary"
+	"testing"
+)
+
+// newTestSfx - sfx with a single square wave note held for speed ticks
+func newTestSfx(pitch uint8, speed uint8) SfxPattern {
+	sfx := SfxPattern{Speed: speed}
+	sfx.Notes[0] = Note{Pitch: pitch, Waveform: WAVE_SQUARE, Volume: 7}
+	return sfx
+}
+
+func TestSynthSilentWhenIdle(t *testing.T) {
+	s := newSynth()
+	buf := make([]int16, 1000)
+	s.render(buf)
+	for i, sample := range buf {
+		if sample != 0 {
+			t.Fatalf("Expected silence at sample %d got: %d", i, sample)
+		}
+	}
+}
+
+func TestSynthPitch(t *testing.T) {
+	s := newSynth()
+	if err := s.setSfx(0, newTestSfx(33, 255)); err != nil {
+		t.Fatalf("Failed to set sfx: %s", err)
+	}
+	s.play(0, -1, 0)
+
+	// one second of A at 440Hz should cross zero twice per cycle
+	buf := make([]int16, _sampleRate)
+	s.render(buf)
+	crossings := 0
+	for i := 1; i < len(buf); i++ {
+		if (buf[i-1] < 0) != (buf[i] < 0) {
+			crossings++
+		}
+	}
+	if crossings < 878 || crossings > 882 {
+		t.Errorf("Expected about %d zero crossings got: %d", 880, crossings)
+	}
+}
+
+func TestSynthSfxLength(t *testing.T) {
+	s := newSynth()
+	s.setSfx(1, newTestSfx(24, 1))
+	s.play(1, 2, 0)
+
+	if s.channels[2].sfx != 1 {
+		t.Fatalf("Expected sfx %d on channel %d got: %d", 1, 2, s.channels[2].sfx)
+	}
+
+	// 32 notes of 1 tick each
+	buf := make([]int16, _sfxNotes*_samplesPerTick-1)
+	s.render(buf)
+	if s.channels[2].sfx != 1 {
+		t.Errorf("Expected sfx to still be playing")
+	}
+	s.render(buf[:1])
+	if s.channels[2].sfx != -1 {
+		t.Errorf("Expected sfx to have finished got: %d", s.channels[2].sfx)
+	}
+}
+
+func TestSynthLoopRelease(t *testing.T) {
+	s := newSynth()
+	sfx := newTestSfx(24, 1)
+	sfx.LoopStart = 0
+	sfx.LoopEnd = 4
+	s.setSfx(0, sfx)
+	s.play(0, 0, 0)
+
+	buf := make([]int16, _sfxNotes*_samplesPerTick*2)
+	s.render(buf)
+	if s.channels[0].sfx != 0 {
+		t.Fatalf("Expected looping sfx to still be playing")
+	}
+
+	// releasing plays on to the end of the sfx
+	s.play(-2, 0, 0)
+	s.render(buf)
+	if s.channels[0].sfx != -1 {
+		t.Errorf("Expected released sfx to have finished")
+	}
+
+	// stop all channels
+	s.play(0, 0, 0)
+	s.play(0, 1, 0)
+	s.play(-1, -1, 0)
+	for i, ch := range s.channels {
+		if ch.sfx != -1 {
+			t.Errorf("Expected channel %d to be stopped", i)
+		}
+	}
+}
+
+func TestSynthFreeChannel(t *testing.T) {
+	s := newSynth()
+	for n := 0; n < 3; n++ {
+		s.setSfx(n, newTestSfx(24, 8))
+	}
+	s.play(0, -1, 0)
+	s.play(1, -1, 0)
+	// restarting a playing sfx reuses its channel
+	s.play(0, -1, 0)
+	s.play(2, -1, 0)
+
+	expected := []int{0, 1, 2, -1}
+	for i, ch := range s.channels {
+		if ch.sfx != expected[i] {
+			t.Errorf("Expected channel %d to play: %d got: %d", i, expected[i], ch.sfx)
+		}
+	}
+}
+
+func TestSynthRead(t *testing.T) {
+	s := newSynth()
+	s.setSfx(0, newTestSfx(33, 8))
+	s.play(0, 0, 0)
+
+	b := make([]byte, 4*100+3)
+	n, err := s.Read(b)
+	if err != nil {
+		t.Fatalf("Failed to read: %s", err)
+	}
+	if n != 400 {
+		t.Fatalf("Expected to read %d bytes got: %d", 400, n)
+	}
+	for i := 0; i < n; i += 4 {
+		left := binary.LittleEndian.Uint16(b[i:])
+		right := binary.LittleEndian.Uint16(b[i+2:])
+		if left != right {
+			t.Fatalf("Expected left and right samples to match at %d", i)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package console
+
+import (
+	"image"
+	"math"
+)
+
+// Mapper methods
+
+// Map - draws a section of the tilemap at screen position sx, sy
+// each cell is drawn as an 8x8 sprite, empty cells (sprite 0) are skipped
+// when layerMask is non zero only sprites with all of those flag bits set are drawn
+func (p *pixelBuffer) Map(cellX, cellY, sx, sy, cellW, cellH int, layerMask uint8) {
+	for cy := 0; cy < cellH; cy++ {
+		for cx := 0; cx < cellW; cx++ {
+			n := p.MGet(cellX+cx, cellY+cy)
+			if n == 0 {
+				continue
+			}
+			if layerMask != 0 && spriteFlags(n)&layerMask != layerMask {
+				continue
+			}
+			x := sx + cx*_spriteWidth
+			y := sy + cy*_spriteHeight
+			p.Sprite(n, x, y, 1, 1, _spriteWidth, _spriteHeight)
+		}
+	}
+}
+
+// Tline - draws a line textured with map pixels, starting at map cell mx, my
+// map coords step by mdx, mdy per pixel, a step of 1/8 is one pixel of a sprite
+// empty cells (sprite 0) & transparent colors are not drawn
+func (p *pixelBuffer) Tline(x0, y0, x1, y1 int, mx, my, mdx, mdy float64) {
+	x0, y0 = x0-p.camera.x, y0-p.camera.y
+	x1, y1 = x1-p.camera.x, y1-p.camera.y
+
+	dx, dy := x1-x0, y1-y0
+	steps := int(math.Max(math.Abs(float64(dx)), math.Abs(float64(dy))))
+	for i := 0; i <= steps; i++ {
+		x, y := x0, y0
+		if steps > 0 {
+			x += int(math.Floor(float64(dx*i)/float64(steps) + 0.5))
+			y += int(math.Floor(float64(dy*i)/float64(steps) + 0.5))
+		}
+		if colorID, ok := p.mapPixel(mx, my); ok {
+			p.plot(x, y, colorID)
+		}
+		mx += mdx
+		my += mdy
+	}
+}
+
+// mapPixel - returns color of the sprite pixel at fractional map cell mx, my
+// and whether it is drawn
+func (p *pixelBuffer) mapPixel(mx, my float64) (ColorID, bool) {
+	cellX, cellY := math.Floor(mx), math.Floor(my)
+	n := p.MGet(int(cellX), int(cellY))
+	if n == 0 {
+		return 0, false
+	}
+	x := (n%spritesPerLine())*_spriteWidth + int((mx-cellX)*_spriteWidth)
+	y := (n/spritesPerLine())*_spriteHeight + int((my-cellY)*_spriteHeight)
+	sheet := spriteSheet()
+	if !(image.Point{X: x, Y: y}).In(sheet.Bounds()) {
+		return 0, false
+	}
+	c := sheet.ColorIndexAt(x, y)
+	if int(c) >= len(p.transparent) || p.transparent[c] {
+		return 0, false
+	}
+	return ColorID(c), true
+}
+
+// MGet - returns sprite number at map cell x, y
+func (p *pixelBuffer) MGet(x, y int) int {
+	if !inMap(x, y) {
+		return 0
+	}
+	return int(_console.tilemap[y*_console.Config.MapWidth+x])
+}
+
+// MSet - sets sprite number at map cell x, y
+func (p *pixelBuffer) MSet(x, y, n int) {
+	if !inMap(x, y) {
+		return
+	}
+	_console.tilemap[y*_console.Config.MapWidth+x] = uint8(n)
+}
+
+// inMap - checks cell coords are inside the tilemap
+func inMap(x, y int) bool {
+	return x >= 0 && y >= 0 && x < _console.Config.MapWidth && y < _console.Config.MapHeight
+}
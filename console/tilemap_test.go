@@ -0,0 +1,121 @@
+package console
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestMapGetSet(t *testing.T) {
+	Init(PICO8)
+
+	_console.pb.MSet(2, 3, 17)
+	if got := _console.pb.MGet(2, 3); got != 17 {
+		t.Errorf("Expected cell value: %d got: %d", 17, got)
+	}
+
+	// out of bounds cells are ignored
+	_console.pb.MSet(-1, 0, 5)
+	_console.pb.MSet(_console.Config.MapWidth, 0, 5)
+	if got := _console.pb.MGet(-1, 0); got != 0 {
+		t.Errorf("Expected out of bounds value: %d got: %d", 0, got)
+	}
+	if got := _console.pb.MGet(0, _console.Config.MapHeight); got != 0 {
+		t.Errorf("Expected out of bounds value: %d got: %d", 0, got)
+	}
+}
+
+func TestMapDrawsSprites(t *testing.T) {
+	Init(PICO8)
+	pb := _console.pb
+
+	// draw sprite directly
+	pb.Cls()
+	pb.Sprite(1, 16, 24, 1, 1, 8, 8)
+	expected := make([]uint8, len(pb.pixelSurface.Pix))
+	copy(expected, pb.pixelSurface.Pix)
+
+	// draw same sprite via the map
+	pb.Cls()
+	pb.MSet(2, 3, 1)
+	pb.Map(0, 0, 0, 0, 16, 16, 0)
+
+	if !bytes.Equal(expected, pb.pixelSurface.Pix) {
+		t.Errorf("Map output does not match sprite output")
+	}
+}
+
+func TestMapLayerMask(t *testing.T) {
+	Init(PICO8)
+	pb := _console.pb
+
+	pb.FSet(1, 2, true)
+	if !pb.FGet(1, 2) {
+		t.Errorf("Expected flag %d to be set on sprite %d", 2, 1)
+	}
+	if pb.FGet(1, 3) {
+		t.Errorf("Expected flag %d to be clear on sprite %d", 3, 1)
+	}
+
+	pb.Cls()
+	pb.MSet(0, 0, 1)
+
+	// sprite has no flag bit 3 so nothing should be drawn
+	pb.Map(0, 0, 0, 0, 1, 1, 1<<3)
+	for i, pix := range pb.pixelSurface.Pix {
+		if pix != 0 {
+			t.Fatalf("Expected empty surface, pixel %d is %d", i, pix)
+		}
+	}
+
+	// sprite has flag bit 2 so it should be drawn
+	pb.Map(0, 0, 0, 0, 1, 1, 1<<2)
+	drawn := false
+	for _, pix := range pb.pixelSurface.Pix {
+		if pix != 0 {
+			drawn = true
+			break
+		}
+	}
+	if !drawn {
+		t.Errorf("Expected sprite to be drawn for matching layer")
+	}
+
+	pb.FSet(1, 2, false)
+	if pb.FGet(1, 2) {
+		t.Errorf("Expected flag %d to be cleared on sprite %d", 2, 1)
+	}
+}
+
+func TestTline(t *testing.T) {
+	Init(PICO8)
+	pb := _console.pb
+
+	// sprite 1 top row is colors 0-7
+	for x := 0; x < 8; x++ {
+		_console.sprites[userSpriteBank1].SetColorIndex(8+x, 0, uint8(x))
+	}
+	pb.MSet(0, 0, 1)
+
+	// one sprite pixel per screen pixel, color 0 is transparent
+	pb.Cls(PICO8_PEACH)
+	pb.Tline(0, 0, 7, 0, 0, 0, 1.0/8, 0)
+	if pb.PGet(0, 0) != PICO8_PEACH {
+		t.Errorf("Expected transparent pixel got: %d", pb.PGet(0, 0))
+	}
+	for x := 1; x < 8; x++ {
+		if got := pb.PGet(x, 0); got != ColorID(x) {
+			t.Errorf("Expected pixel %d to be: %d got: %d", x, x, got)
+		}
+	}
+
+	// stepping 2 sprite pixels per screen pixel, past the cell is empty
+	pb.Cls(PICO8_PEACH)
+	pb.Tline(0, 1, 0, 8, 0.125, 0, 0, 0)
+	pb.Tline(0, 2, 7, 2, 0.125, 0, 0.25, 0)
+	if pb.PGet(0, 1) != 1 || pb.PGet(0, 8) != 1 {
+		t.Errorf("Expected vertical line of color 1 got: %d, %d", pb.PGet(0, 1), pb.PGet(0, 8))
+	}
+	if pb.PGet(1, 2) != 3 || pb.PGet(3, 2) != 7 || pb.PGet(4, 2) != PICO8_PEACH {
+		t.Errorf("Expected colors 3, 7 then empty cell got: %d, %d, %d", pb.PGet(1, 2), pb.PGet(3, 2), pb.PGet(4, 2))
+	}
+}
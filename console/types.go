@@ -18,6 +18,7 @@ type ColorID uint8
 type PicoGraphicsAPI interface {
 	Clearer
 	Drawer
+	Mapper
 	Paletter
 	Peeker
 	Printer
@@ -25,7 +26,27 @@ type PicoGraphicsAPI interface {
 }
 
 type PicoInputAPI interface {
-	Btn(id int) bool
+	Btn(id int, player ...int) bool  // Is button pressed
+	Btnp(id int, player ...int) bool // Was button just pressed, repeats while held
+	Stat(n int) int                  // Query console state eg. mouse position
+}
+
+type PicoAudioAPI interface {
+	Sfx(n, channel, offset int)                 // Play sound effect
+	SetSfx(n int, sfx SfxPattern) error         // Define sound effect
+	Music(n, fadeMs, channelMask int)           // Play music from pattern
+	SetMusic(n int, pattern MusicPattern) error // Define music pattern
+}
+
+type PicoMathAPI interface {
+	Sin(x float64) float64        // Sine of x turns, inverted for screen coords
+	Cos(x float64) float64        // Cosine of x turns
+	Atan2(dx, dy float64) float64 // Angle in turns of vector dx,dy
+	Rnd(max float64) float64      // Random number from 0 to max (exclusive)
+	Srand(seed int64)             // Seed random number generator
+	Mid(x, y, z float64) float64  // Middle value of x, y & z
+	Flr(x float64) float64        // Round down to integer
+	Sgn(x float64) float64        // Sign of x, 1 or -1
 }
 
 type Clearer interface {
@@ -34,6 +55,14 @@ type Clearer interface {
 
 type Drawer interface {
 	SetColor(colorID ColorID) // Set drawing color (colour!!!)
+	Camera(x, y int)          // Set camera offset applied to all drawing
+	Clip(x, y, w, h int)      // Set clipping rectangle for all drawing
+	// Set 4x4 fill pattern for shapes, set bits use secondary color or are transparent
+	Fillp(pattern uint16, transparent bool, secondary ...ColorID)
+	Pal(c0, c1 ColorID, mode ...int)        // Remap color in draw palette, or screen palette with PAL_SCREEN
+	PalReset()                              // Reset draw & screen palettes and transparency
+	Palt(colorID ColorID, transparent bool) // Set whether color is transparent in sprites
+	PaltReset()                             // Reset transparency so only color 0 is transparent
 	// drawing primitives
 	Circle(x, y, r int, colorID ...ColorID)
 	CircleFill(x, y, r int, colorID ...ColorID)
@@ -42,6 +71,22 @@ type Drawer interface {
 	PSet(x, y int, colorID ...ColorID)
 	Rect(x0, y0, x1, y1 int, colorID ...ColorID)
 	RectFill(x0, y0, x1, y1 int, colorID ...ColorID)
+	Poly(points []image.Point, colorID ...ColorID)
+	PolyFill(points []image.Point, colorID ...ColorID)
+	Tri(x0, y0, x1, y1, x2, y2 int, colorID ...ColorID)
+	TriFill(x0, y0, x1, y1, x2, y2 int, colorID ...ColorID)
+	Oval(x0, y0, x1, y1 int, colorID ...ColorID)
+	OvalFill(x0, y0, x1, y1 int, colorID ...ColorID)
+	RoundRect(x0, y0, x1, y1, r int, colorID ...ColorID)
+	RoundRectFill(x0, y0, x1, y1, r int, colorID ...ColorID)
+}
+
+type Mapper interface {
+	Map(cellX, cellY, sx, sy, cellW, cellH int, layerMask uint8) // Draw section of map
+	MGet(x, y int) int                                           // Get map cell sprite number
+	MSet(x, y, n int)                                            // Set map cell sprite number
+	// Draw line textured with map pixels, map coords step by mdx, mdy per pixel
+	Tline(x0, y0, x1, y1 int, mx, my, mdx, mdy float64)
 }
 
 type Paletter interface {
@@ -53,11 +98,18 @@ type Paletter interface {
 	GetColors() []color.Color
 	MapColor(fromColor ColorID, toColor ColorID) error
 	SetTransparent(color ColorID, enabled bool) error
+	SetPalette(rgb ...uint32) error // Replace palette with 0xRRGGBB colors
 }
 
 type Peeker interface {
-	Peek(pos int) uint8
-	Poke(pos int, value uint8)
+	Peek(addr int) uint8                      // Read byte from memory
+	Poke(addr int, value uint8)               // Write byte to memory
+	Peek2(addr int) int                       // Read 16 bit signed value
+	Poke2(addr int, value int)                // Write 16 bit value
+	Peek4(addr int) Fix                       // Read 32 bit fixed point value
+	Poke4(addr int, value Fix)                // Write 32 bit fixed point value
+	Memcpy(dest, src, length int)             // Copy memory, regions may overlap
+	Memset(dest int, value uint8, length int) // Set memory to value
 }
 
 type Printer interface {
@@ -70,9 +122,17 @@ type Printer interface {
 }
 
 type Spriter interface {
+	FGet(n, bit int) bool    // Get sprite flag
+	FSet(n, bit int, v bool) // Set sprite flag
 	Sprite(n, x, y, w, h, dw, dh int)
 	SpriteFlipped(n, x, y, w, h, dw, dh int, flipX, flipY bool)
 	SpriteRotated(n, x, y, w, h, dw, dh, rot int)
+	// Draw pixel rect from sprite sheet scaled to dw, dh
+	Sspr(sx, sy, sw, sh, dx, dy, dw, dh int, flipX, flipY bool)
+	LoadSprites(data []byte) (int, error)         // Add sprite bank from png data
+	LoadSpritesFile(filename string) (int, error) // Add sprite bank from png file
+	SetSpriteBank(bank int) error                 // Select sprite bank for drawing
+	GetSpriteBank() int                           // Get sprite bank used for drawing
 }
 
 type ConsoleType string
@@ -94,8 +154,12 @@ var ConsoleTypes = map[ConsoleType]string{
 	CBM64:      "CBM64",
 }
 
+// TOTAL_COLORS - colors in the base palette of every console
 const TOTAL_COLORS = 16
 
+// MAX_COLORS - largest palette supported
+const MAX_COLORS = 256
+
 type Configger interface {
 	GetConfig() Config
 }
@@ -105,6 +169,8 @@ type Cartridge interface {
 	Configger
 	initPb(pb PixelBuffer)
 	PicoInputAPI
+	PicoAudioAPI
+	PicoMathAPI
 	// User implemented methods below
 	Init() error
 	Render()
@@ -0,0 +1,58 @@
+package console
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+)
+
+// WriteWAV - writes mono 16 bit samples as a WAV file at the console sample rate
+func WriteWAV(w io.Writer, samples []int16) error {
+	const (
+		channels      = 1
+		bitsPerSample = 16
+	)
+	dataSize := uint32(len(samples) * 2)
+	blockAlign := uint16(channels * bitsPerSample / 8)
+
+	header := []interface{}{
+		[4]byte{'R', 'I', 'F', 'F'},
+		uint32(36 + dataSize),
+		[4]byte{'W', 'A', 'V', 'E'},
+		// format chunk
+		[4]byte{'f', 'm', 't', ' '},
+		uint32(16), // chunk size
+		uint16(1),  // PCM
+		uint16(channels),
+		uint32(_sampleRate),
+		uint32(_sampleRate) * uint32(blockAlign), // byte rate
+		blockAlign,
+		uint16(bitsPerSample),
+		// data chunk
+		[4]byte{'d', 'a', 't', 'a'},
+		dataSize,
+	}
+	for _, field := range header {
+		if err := binary.Write(w, binary.LittleEndian, field); err != nil {
+			return fmt.Errorf("Error writing WAV header: %s", err)
+		}
+	}
+	if err := binary.Write(w, binary.LittleEndian, samples); err != nil {
+		return fmt.Errorf("Error writing WAV samples: %s", err)
+	}
+	return nil
+}
+
+// SaveWAV - saves mono 16 bit samples to a WAV file
+func SaveWAV(filename string, samples []int16) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("Error creating WAV file: %s", err)
+	}
+	if err := WriteWAV(f, samples); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
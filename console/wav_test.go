@@ -0,0 +1,77 @@
+package console
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+func TestWriteWAV(t *testing.T) {
+	samples := []int16{0, 1000, -1000, 32767, -32768}
+	buf := &bytes.Buffer{}
+	if err := WriteWAV(buf, samples); err != nil {
+		t.Fatalf("Failed to write WAV: %s", err)
+	}
+
+	data := buf.Bytes()
+	if len(data) != 44+len(samples)*2 {
+		t.Fatalf("Expected WAV size: %d got: %d", 44+len(samples)*2, len(data))
+	}
+	if string(data[0:4]) != "RIFF" || string(data[8:12]) != "WAVE" || string(data[36:40]) != "data" {
+		t.Errorf("Invalid WAV header: %q", data[:44])
+	}
+	if rate := binary.LittleEndian.Uint32(data[24:]); rate != _sampleRate {
+		t.Errorf("Expected sample rate: %d got: %d", _sampleRate, rate)
+	}
+	for i, expected := range samples {
+		got := int16(binary.LittleEndian.Uint16(data[44+i*2:]))
+		if got != expected {
+			t.Errorf("Expected sample %d to be: %d got: %d", i, expected, got)
+		}
+	}
+}
+
+func TestRenderAudioDeterministic(t *testing.T) {
+	Init(PICO8)
+	sfx := SfxPattern{Speed: 4}
+	for i := range sfx.Notes {
+		sfx.Notes[i] = Note{Pitch: uint8(i + 20), Waveform: uint8(i % 8), Volume: 5, Effect: uint8(i % 8)}
+	}
+	_console.SetSfx(3, sfx)
+	_console.SetMusic(0, NewMusicPattern(3, 3))
+
+	first, err := RenderAudio(0, 0.5)
+	if err != nil {
+		t.Fatalf("Failed to render audio: %s", err)
+	}
+	second, err := RenderAudio(0, 0.5)
+	if err != nil {
+		t.Fatalf("Failed to render audio: %s", err)
+	}
+	if len(first) != _sampleRate/2 {
+		t.Fatalf("Expected %d samples got: %d", _sampleRate/2, len(first))
+	}
+
+	silent := true
+	for i := range first {
+		if first[i] != second[i] {
+			t.Fatalf("Expected identical renders, sample %d differs: %d vs %d", i, first[i], second[i])
+		}
+		if first[i] != 0 {
+			silent = false
+		}
+	}
+	if silent {
+		t.Errorf("Expected rendered music not to be silent")
+	}
+
+	if _, err := RenderSfx(3, 0.1); err != nil {
+		t.Errorf("Failed to render sfx: %s", err)
+	}
+	if _, err := RenderAudio(_musicCount, 1); err == nil {
+		t.Errorf("Expected error for invalid pattern")
+	}
+	if _, err := RenderSfx(0, 0); err == nil {
+		t.Errorf("Expected error for invalid duration")
+	}
+}
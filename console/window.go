@@ -0,0 +1,179 @@
+//go:build js || !headless
+// +build js !headless
+
+package console
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/hajimehoshi/ebiten"
+	"github.com/hajimehoshi/ebiten/audio"
+	"github.com/hajimehoshi/ebiten/ebitenutil"
+)
+
+/*
+	Window - the console runs in an ebiten window with an audio device
+
+	Builds with the headless tag have no window, they only link the
+	headless console so it can be tested without a display or cgo.
+	Browser builds always have a window.
+*/
+
+// window - ebiten screen, audio & hotkey state of a running console
+type window struct {
+	screen      *ebiten.Image
+	audioPlayer *audio.Player
+	hotkeys     map[ebiten.Key]bool // previous state of console hotkeys
+}
+
+// Run - runs cart in a window until it is closed
+func Run(cart Cartridge) error {
+	_console.hotkeys = make(map[ebiten.Key]bool)
+
+	// start sound, carts still run without an audio device
+	player, err := startAudio(_console.synth)
+	if err != nil {
+		log.Printf("Failed to start audio: %s", err)
+	}
+	_console.audioPlayer = player
+
+	// load cartridge
+	if err := _console.loadCart(cart); err != nil {
+		return err
+	}
+
+	// editor menu items are posted to the cart
+	listenForMessages()
+
+	// poll events
+	endFrame = time.Now() // init end frame
+	startFrame = time.Now()
+
+	return ebiten.Run(_console.update, _console.Config.ConsoleWidth, _console.Config.ConsoleHeight, 1, "pico-go")
+}
+
+// update - called by ebiten every frame, dropped frames are only skipped when running slowly
+func (c *console) update(screen *ebiten.Image) error {
+	c.screen = screen
+	if ebiten.IsRunningSlowly() {
+		c.skipFrame()
+		return nil
+	}
+
+	if err := c.handleInput(); err != nil {
+		return err
+	}
+
+	return c.frame()
+}
+
+func (c *console) handleInput() error {
+
+	// This method is called every iteration
+	// it samples the button state for the cart and
+	// checks system wide input events such as the escape key
+
+	// mouse coords are transformed into console pixel coords
+	mx, my := c.pb.windowToPixel(ebiten.CursorPosition())
+	_, wheelY := ebiten.Wheel()
+
+	c.Lock()
+	if c.input == nil {
+		c.buttons.sample(isKeyPressed)
+		c.mouse.sample(mx, my, isMouseButtonPressed, wheelY)
+	}
+	c.Unlock()
+
+	// 	// TODO keys to implement
+	// 	// F7 Capture cartridge label image
+	// 	// F8 Start recording a video
+
+	// F6 Save PNG screenshot
+	if c.hotkeyPressed(ebiten.KeyF6) {
+		c.takeScreenshot()
+	}
+
+	// F9 Save GIF video (max: 8 seconds by default)
+	if c.hotkeyPressed(ebiten.KeyF9) {
+		if filename, err := c.saveVideo(); err != nil {
+			log.Printf("Failed to save video: %s", err)
+		} else {
+			log.Printf("Saved video: %s", filename)
+		}
+	}
+
+	// F10 Save input recording for replay
+	if c.hotkeyPressed(ebiten.KeyF10) {
+		if filename, err := c.saveRecording(); err != nil {
+			log.Printf("Failed to save input recording: %s", err)
+		} else {
+			log.Printf("Saved input recording: %s", filename)
+		}
+	}
+
+	return nil
+}
+
+// hotkeyPressed - true on the frame a console hotkey goes down
+func (c *console) hotkeyPressed(key ebiten.Key) bool {
+	down := ebiten.IsKeyPressed(key)
+	pressed := down && !c.hotkeys[key]
+	c.hotkeys[key] = down
+	return pressed
+}
+
+// display - copies the frame to the window
+func (p *pixelBuffer) display() {
+	_console.screen.ReplacePixels(p.rgbaPixels)
+	if _console.showFPS {
+		ebitenutil.DebugPrint(_console.screen, fmt.Sprintf("FPS: %f", ebiten.CurrentFPS()))
+	}
+}
+
+// startAudio - streams the synth output to the audio device
+func startAudio(s *synth) (*audio.Player, error) {
+	context, err := audio.NewContext(_sampleRate)
+	if err != nil {
+		return nil, err
+	}
+	player, err := audio.NewPlayer(context, s)
+	if err != nil {
+		return nil, err
+	}
+	if err := player.Play(); err != nil {
+		return nil, err
+	}
+	return player, nil
+}
+
+// ebitenKeys - ebiten key for each console key, matched by name
+var ebitenKeys = func() map[Key]ebiten.Key {
+	keys := make(map[Key]ebiten.Key, len(keyNames))
+	for key := ebiten.Key(0); key <= ebiten.KeyMax; key++ {
+		if k, ok := keyByName(key.String()); ok {
+			keys[k] = key
+		}
+	}
+	return keys
+}()
+
+// isKeyPressed - true while console key is down
+func isKeyPressed(key Key) bool {
+	k, ok := ebitenKeys[key]
+	return ok && ebiten.IsKeyPressed(k)
+}
+
+// ebitenMouseButtons - ebiten button for each console mouse button bit
+var ebitenMouseButtons = map[int]ebiten.MouseButton{
+	MOUSE_LEFT:   ebiten.MouseButtonLeft,
+	MOUSE_RIGHT:  ebiten.MouseButtonRight,
+	MOUSE_MIDDLE: ebiten.MouseButtonMiddle,
+}
+
+// isMouseButtonPressed - true while console mouse button is down
+func isMouseButtonPressed(button int) bool {
+	b, ok := ebitenMouseButtons[button]
+	return ok && ebiten.IsMouseButtonPressed(b)
+}
@@ -4,6 +4,7 @@ const (
 	defaultCodeDir    = "gosrc"
 	defaultSourceFile = "main.go"
 	defaultCompileDir = "js"
+	defaultCaptureDir = "captures"
 	defaultOutputFile = "cart.js"
 	ebitenRepo        = "github.com/hajimehoshi/ebiten"
 )
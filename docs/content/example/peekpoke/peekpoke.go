@@ -40,9 +40,11 @@ func (c *cartridge) Render() {
 
 	c.PrintAt("PEEK POKE:", 40, 5, console.PICO8_WHITE)
 
-	for i := 0; i < 128*128; i++ {
-		pos0Value := c.Peek(i)
-		pos0Value++
-		c.Poke(i, pos0Value)
+	// screen memory holds 2 pixels per byte, cycle the color of both
+	for addr := console.MEM_SCREEN; addr < console.MEM_SCREEN+128*64; addr++ {
+		value := c.Peek(addr)
+		left := (value + 1) & 0x0f
+		right := (value>>4 + 1) & 0x0f
+		c.Poke(addr, right<<4|left)
 	}
 }
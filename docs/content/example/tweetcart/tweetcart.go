@@ -59,9 +59,9 @@ func (c *cartridge) renderLoop() {
 		for y := -64; y < 64; y += 3 {
 			d := 2 * (y % 2)
 			for x := -64 + d; x < 64+d; x += 4 {
-				a := math.Atan2(float64(x)*math.Pi*2, float64(y)*math.Pi*2)
+				a := c.Atan2(float64(x), float64(y))
 				r := math.Sqrt(float64(x*x+y*y)) / 128
-				col := int(4 * r / math.Sin(r/4+a*2-t/10*math.Pi*2))
+				col := int(4 * r / c.Sin(r/4+a*2-t/10))
 				c.CircleFill(x+64, y+64, 2, console.ColorID(7+col%7))
 			}
 		}
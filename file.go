@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/asticode/go-astilectron-bootstrap"
@@ -16,6 +17,9 @@ import (
 var watcher *fsnotify.Watcher
 var cancel chan bool
 
+// capturePath - dir running carts save captures to, set by initBackend
+var capturePath string
+
 // load - loads sourcecode from a specific path
 func load(path string) (a Application, err error) {
 
@@ -127,3 +131,37 @@ func save(source SourceCode) (a Application, err error) {
 
 	return
 }
+
+// saveCapture - writes a screenshot, video or replay posted by a running cart
+// to the captures dir, carts only choose the filename
+func saveCapture(capture Capture) (a Application, err error) {
+
+	if capturePath == "" {
+		err = fmt.Errorf("Failed to write capture file - captures dir not initialised")
+		return
+	}
+
+	name := filepath.Base(capture.Name)
+	if name != capture.Name || strings.HasPrefix(name, ".") {
+		err = fmt.Errorf("Name %s is not a valid capture filename, MUST be a filename without a directory", capture.Name)
+		return
+	}
+
+	// carts may only write capture files
+	switch filepath.Ext(name) {
+	case ".png", ".gif", ".pgr":
+	default:
+		err = fmt.Errorf("Name %s is not a valid capture filename, MUST end with .png, .gif or .pgr extension", capture.Name)
+		return
+	}
+
+	path := filepath.Join(capturePath, name)
+	if err = ioutil.WriteFile(path, capture.Data, 0644); err != nil {
+		err = fmt.Errorf("Failed to write capture file - %s - %s\n", path, err)
+		return
+	}
+
+	a.Path = path
+
+	return
+}
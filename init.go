@@ -64,6 +64,14 @@ func initBackend(path string) (a Application, err error) {
 		return
 	}
 
+	// carts running in the editor save their captures here
+	err = os.MkdirAll(filepath.Join(path, defaultCaptureDir), os.FileMode(0755))
+	if err != nil {
+		err = fmt.Errorf("Failed to create captures dir: %s", err)
+		return
+	}
+	capturePath = filepath.Join(path, defaultCaptureDir)
+
 	// check for default source
 	fullSourcePath := filepath.Join(defaultCodePath, defaultSourceFile)
 	if _, err = os.Stat(fullSourcePath); err != nil {
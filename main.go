@@ -179,6 +179,24 @@ func main() {
 							return
 						},
 					},
+					{
+						Accelerator: &astilectron.Accelerator{"F6"},
+						Label:       astilectron.PtrStr("Screenshot"),
+						OnClick: func(e astilectron.Event) (deleteListener bool) {
+							if err := bootstrap.SendMessage(w, "screenshot", "take screenshot", func(m *bootstrap.MessageIn) {
+								var s string
+								if m != nil {
+									if err := json.Unmarshal(m.Payload, &s); err != nil {
+										astilog.Error(errors.Wrap(err, "unmarshaling payload failed"))
+										return
+									}
+								}
+							}); err != nil {
+								astilog.Error(errors.Wrap(err, "sending screenshot event failed"))
+							}
+							return
+						},
+					},
 				},
 			},
 		},
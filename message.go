@@ -22,6 +22,12 @@ type SourceCode struct {
 	Source string `json:"source"`
 }
 
+// Capture - screenshot, video or replay posted by a running cart
+type Capture struct {
+	Name string `json:"name"` // filename only, the backend chooses the dir
+	Data []byte `json:"data"` // base64 encoded in json
+}
+
 // handleMessages handles messages
 func handleMessages(_ *astilectron.Window, m bootstrap.MessageIn) (payload interface{}, err error) {
 	switch m.Name {
@@ -85,6 +91,22 @@ func handleMessages(_ *astilectron.Window, m bootstrap.MessageIn) (payload inter
 			payload = err.Error()
 		}
 		return
+	case "capture":
+		// Unmarshal payload
+		capture := Capture{}
+
+		if len(m.Payload) > 0 {
+			// Unmarshal payload
+			if err = json.Unmarshal(m.Payload, &capture); err != nil {
+				payload = fmt.Sprintf("Failed to unmarshal message: %s - %s", string(m.Payload), err.Error())
+				return
+			}
+		}
+		payload, err = saveCapture(capture)
+		if err != nil {
+			payload = err.Error()
+		}
+		return
 	}
 	return
 }
@@ -8,8 +8,6 @@ package main
 */
 
 import (
-	"math/rand"
-
 	"github.com/telecoda/pico-go-electron/console"
 )
 
@@ -49,7 +47,7 @@ func (c *cartridge) Init() error {
 	c.s = make([]int, w, w)
 
 	for i := 0; i < w; i++ {
-		c.s[i] = rand.Intn(w)
+		c.s[i] = int(c.Rnd(float64(w)))
 	}
 
 	return nil
@@ -78,6 +78,32 @@ let index = {
     runMenu: function(message) {
         this.run();
     },
+    // screenshot menu clicked
+    screenshotMenu: function(message) {
+        // ask the running cart for a screenshot, it posts back a capture message
+        let gameWindow = document.getElementById("gameFrame").contentWindow;
+        let gameCanvas = gameWindow.document.querySelector("canvas");
+        if (gameCanvas === null) {
+            dialog.showErrorBox("Screenshot Error","No cart is running");
+            return
+        }
+        gameWindow.postMessage({"name": "screenshot"}, "*");
+    },
+    // capture - call backend to write a screenshot, video or replay posted by the cart
+    capture: function(payload) {
+        // Create message
+        let message = {"name": "capture",
+            "payload": payload
+        };
+        // Send message
+        astilectron.sendMessage(message, function(message) {
+            // Check error
+            if (message.name === "error") {
+                dialog.showErrorBox("Capture Error",message.payload);
+                return
+            }
+        })
+    },
     // save menu clicked
     saveMenu: function(message) {
         if (typeof filename !== "undefined") {
@@ -211,6 +237,18 @@ let index = {
 
     // meno option listener
     listen: function() {
+        // carts can't write files so they post captures to this window
+        window.addEventListener("message", function(event) {
+            // only the running cart may save captures
+            if (event.source !== document.getElementById("gameFrame").contentWindow) {
+                return
+            }
+            if (event.data === null || event.data.name !== "capture") {
+                return
+            }
+            index.capture(event.data.payload);
+        });
+
         astilectron.onMessage(function(message) {
             switch (message.name) {
                 case "about":
@@ -233,6 +271,10 @@ let index = {
                     index.runMenu(message.payload);
                     return {payload: "run clicked!"};
                     break;
+                case "screenshot":
+                    index.screenshotMenu(message.payload);
+                    return {payload: "screenshot clicked!"};
+                    break;
                 case "save":
                     index.saveMenu(message.payload);
                     return {payload: "save clicked!"};